@@ -0,0 +1,792 @@
+package log
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// The values of structs, maps, slices, arrays and pointers are written by the encoders
+// cached per type, which honour the json tags and delegate the known types to the
+// Entry methods. The types containing channels, functions or complex numbers are
+// not supported and fall back to encoding/json, the struct fields of such types
+// fall back one by one, so the log tags of the other fields are still honoured.
+//
+// A struct field tagged with `log:"-"` is omitted, and with `log:",redact"` is
+// redacted by the Redactor of logger, or written as "[REDACTED]" without Redactor.
+
+type anyEncoder func(e *Entry, p unsafe.Pointer)
+
+type anyCodec struct {
+	enc    anyEncoder
+	elem   anyEncoder // the encoder of pointed value if the type is a pointer
+	direct bool
+}
+
+// anyMaxDepth limits the nesting of pointers, slices and maps.
+const anyMaxDepth = 100
+
+var anyCodecs sync.Map // map[reflect.Type]*anyCodec
+
+func anyCodecOf(t reflect.Type) *anyCodec {
+	if c, ok := anyCodecs.Load(t); ok {
+		return c.(*anyCodec)
+	}
+	c := &anyCodec{
+		enc:    newAnyEncoder(t, make(map[reflect.Type]*anyEncoder)),
+		direct: anyDirect(t),
+	}
+	if c.enc != nil && t.Kind() == reflect.Ptr {
+		c.elem = newAnyEncoder(t.Elem(), make(map[reflect.Type]*anyEncoder))
+	}
+	if c1, loaded := anyCodecs.LoadOrStore(t, c); loaded {
+		return c1.(*anyCodec)
+	}
+	return c
+}
+
+// anyDirect reports whether the values of t are stored directly in the data word of interfaces.
+func anyDirect(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return t.Len() == 1 && anyDirect(t.Elem())
+	case reflect.Struct:
+		return t.NumField() == 1 && anyDirect(t.Field(0).Type)
+	}
+	return false
+}
+
+// encode appends the JSON of value, which type is of the codec.
+func (c *anyCodec) encode(e *Entry, value any) {
+	p := (*[2]unsafe.Pointer)(unsafe.Pointer(&value))[1]
+	switch {
+	case c.elem != nil:
+		if p == nil || !e.enter(p) {
+			e.buf = append(e.buf, "null"...)
+			return
+		}
+		c.elem(e, p)
+		e.leave()
+	case c.direct:
+		// the data word of interface is the value
+		word := p
+		c.enc(e, unsafe.Pointer(&word))
+	default:
+		c.enc(e, p)
+	}
+}
+
+// reflectAny adds the field key with value by the encoder of its type,
+// returns false if the type is not supported.
+func (e *Entry) reflectAny(key string, value any) bool {
+	c := anyCodecOf(reflect.TypeOf(value))
+	if c.enc == nil {
+		return false
+	}
+
+	e.buf = append(e.buf, ',', '"')
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, '"', ':')
+	n := len(e.buf)
+	redactor, enc, redacting := e.redactor, e.enc, e.redacting
+	e.redactor, e.enc, e.redacting = nil, JSONEncoding, e.tagRedactor()
+	c.encode(e, value)
+	e.redactor, e.enc, e.redacting = redactor, enc, redacting
+	if redactor != nil {
+		e.buf = redactor.redactJSON(e.buf, n, key)
+	}
+	return true
+}
+
+// anyValue appends the JSON of value in an interface.
+func (e *Entry) anyValue(value any) {
+	if value == nil {
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+	c := anyCodecOf(reflect.TypeOf(value))
+	if c.enc == nil {
+		n := len(e.buf)
+		e.Any("", value)
+		e.stripKey(n)
+		return
+	}
+	c.encode(e, value)
+}
+
+// enter pushes the pointer p of a value to the pointers being encoded, returns false
+// if p is being encoded or too deep, which means a cyclic value.
+func (e *Entry) enter(p unsafe.Pointer) bool {
+	if len(e.anyptrs) >= anyMaxDepth {
+		return false
+	}
+	for _, p1 := range e.anyptrs {
+		if p1 == p {
+			return false
+		}
+	}
+	e.anyptrs = append(e.anyptrs, p)
+	return true
+}
+
+// leave pops the pointer pushed by enter.
+func (e *Entry) leave() {
+	e.anyptrs[len(e.anyptrs)-1] = nil
+	e.anyptrs = e.anyptrs[:len(e.anyptrs)-1]
+}
+
+// stripKey removes the empty key of a field appended at n, leaves the value only.
+func (e *Entry) stripKey(n int) {
+	// `,"":`
+	e.buf = append(e.buf[:n], e.buf[n+4:]...)
+}
+
+var (
+	anyTimeType            = reflect.TypeOf(time.Time{})
+	anyDurationType        = reflect.TypeOf(time.Duration(0))
+	anyIPType              = reflect.TypeOf(net.IP{})
+	anyIPNetType           = reflect.TypeOf(net.IPNet{})
+	anyHardwareAddrType    = reflect.TypeOf(net.HardwareAddr{})
+	anyNetIPAddrType       = reflect.TypeOf(netip.Addr{})
+	anyNetIPAddrPortType   = reflect.TypeOf(netip.AddrPort{})
+	anyNetIPPrefixType     = reflect.TypeOf(netip.Prefix{})
+	anyRawMessageType      = reflect.TypeOf(json.RawMessage{})
+	anyNumberType          = reflect.TypeOf(json.Number(""))
+	anyObjectMarshalerType = reflect.TypeOf((*ObjectMarshaler)(nil)).Elem()
+	anyJSONMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	anyTextMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// newAnyEncoder returns the encoder of t, or nil if t is not supported.
+func newAnyEncoder(t reflect.Type, building map[reflect.Type]*anyEncoder) anyEncoder {
+	if enc, ok := building[t]; ok {
+		// recursive type
+		return func(e *Entry, p unsafe.Pointer) {
+			(*enc)(e, p)
+		}
+	}
+
+	switch t {
+	case anyTimeType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.Time("", *(*time.Time)(p))
+			e.stripKey(n)
+		}
+	case anyDurationType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.Dur("", *(*time.Duration)(p))
+			e.stripKey(n)
+		}
+	case anyIPType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.IPAddr("", *(*net.IP)(p))
+			e.stripKey(n)
+		}
+	case anyIPNetType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.IPPrefix("", *(*net.IPNet)(p))
+			e.stripKey(n)
+		}
+	case anyHardwareAddrType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.MACAddr("", *(*net.HardwareAddr)(p))
+			e.stripKey(n)
+		}
+	case anyNetIPAddrType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.NetIPAddr("", *(*netip.Addr)(p))
+			e.stripKey(n)
+		}
+	case anyNetIPAddrPortType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.NetIPAddrPort("", *(*netip.AddrPort)(p))
+			e.stripKey(n)
+		}
+	case anyNetIPPrefixType:
+		return func(e *Entry, p unsafe.Pointer) {
+			n := len(e.buf)
+			e.NetIPPrefix("", *(*netip.Prefix)(p))
+			e.stripKey(n)
+		}
+	case anyRawMessageType:
+		return func(e *Entry, p unsafe.Pointer) {
+			if b := *(*json.RawMessage)(p); len(b) != 0 {
+				e.buf = append(e.buf, b...)
+			} else {
+				e.buf = append(e.buf, "null"...)
+			}
+		}
+	case anyNumberType:
+		return func(e *Entry, p unsafe.Pointer) {
+			s := string(*(*json.Number)(p))
+			if s == "" {
+				s = "0"
+			}
+			if c, l := s[0], s[len(s)-1]; c != '-' && (c < '0' || c > '9') || l < '0' || l > '9' || !json.Valid([]byte(s)) {
+				e.buf = append(e.buf, `"marshaling error: json: invalid number literal `...)
+				e.string(strconv.Quote(s))
+				e.buf = append(e.buf, '"')
+				return
+			}
+			e.buf = append(e.buf, s...)
+		}
+	}
+
+	if t.Kind() != reflect.Interface {
+		if enc := newAnyMarshalerEncoder(t); enc != nil {
+			return enc
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendBool(e.buf, *(*bool)(p))
+		}
+	case reflect.Int:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendInt(e.buf, int64(*(*int)(p)), 10)
+		}
+	case reflect.Int8:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendInt(e.buf, int64(*(*int8)(p)), 10)
+		}
+	case reflect.Int16:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendInt(e.buf, int64(*(*int16)(p)), 10)
+		}
+	case reflect.Int32:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendInt(e.buf, int64(*(*int32)(p)), 10)
+		}
+	case reflect.Int64:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendInt(e.buf, *(*int64)(p), 10)
+		}
+	case reflect.Uint:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, uint64(*(*uint)(p)), 10)
+		}
+	case reflect.Uint8:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, uint64(*(*uint8)(p)), 10)
+		}
+	case reflect.Uint16:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, uint64(*(*uint16)(p)), 10)
+		}
+	case reflect.Uint32:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, uint64(*(*uint32)(p)), 10)
+		}
+	case reflect.Uint64:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, *(*uint64)(p), 10)
+		}
+	case reflect.Uintptr:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = strconv.AppendUint(e.buf, uint64(*(*uintptr)(p)), 10)
+		}
+	case reflect.Float32:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = appendFloat(e.buf, float64(*(*float32)(p)), 32)
+		}
+	case reflect.Float64:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = appendFloat(e.buf, *(*float64)(p), 64)
+		}
+	case reflect.String:
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = append(e.buf, '"')
+			e.string(*(*string)(p))
+			e.buf = append(e.buf, '"')
+		}
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return func(e *Entry, p unsafe.Pointer) {
+				e.anyValue(*(*any)(p))
+			}
+		}
+		return func(e *Entry, p unsafe.Pointer) {
+			e.anyValue(reflect.NewAt(t, p).Elem().Interface())
+		}
+	case reflect.Ptr:
+		elem := newAnyEncoder(t.Elem(), building)
+		if elem == nil {
+			return nil
+		}
+		return func(e *Entry, p unsafe.Pointer) {
+			if p = *(*unsafe.Pointer)(p); p == nil || !e.enter(p) {
+				e.buf = append(e.buf, "null"...)
+				return
+			}
+			elem(e, p)
+			e.leave()
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && newAnyMarshalerEncoder(t.Elem()) == nil {
+			// base64 as encoding/json
+			return func(e *Entry, p unsafe.Pointer) {
+				b := *(*[]byte)(p)
+				if b == nil {
+					e.buf = append(e.buf, "null"...)
+					return
+				}
+				e.buf = append(e.buf, '"')
+				n := len(e.buf)
+				e.buf = append(e.buf, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+				base64.StdEncoding.Encode(e.buf[n:], b)
+				e.buf = append(e.buf, '"')
+			}
+		}
+		elem := newAnyEncoder(t.Elem(), building)
+		if elem == nil {
+			return nil
+		}
+		size := t.Elem().Size()
+		return func(e *Entry, p unsafe.Pointer) {
+			s := (*anySlice)(p)
+			if s.Data == nil || !e.enter(s.Data) {
+				e.buf = append(e.buf, "null"...)
+				return
+			}
+			e.buf = append(e.buf, '[')
+			for i := 0; i < s.Len; i++ {
+				if i > 0 {
+					e.buf = append(e.buf, ',')
+				}
+				elem(e, unsafe.Pointer(uintptr(s.Data)+uintptr(i)*size))
+			}
+			e.buf = append(e.buf, ']')
+			e.leave()
+		}
+	case reflect.Array:
+		elem := newAnyEncoder(t.Elem(), building)
+		if elem == nil {
+			return nil
+		}
+		size, length := t.Elem().Size(), t.Len()
+		return func(e *Entry, p unsafe.Pointer) {
+			e.buf = append(e.buf, '[')
+			for i := 0; i < length; i++ {
+				if i > 0 {
+					e.buf = append(e.buf, ',')
+				}
+				elem(e, unsafe.Pointer(uintptr(p)+uintptr(i)*size))
+			}
+			e.buf = append(e.buf, ']')
+		}
+	case reflect.Map:
+		return newAnyMapEncoder(t, building)
+	case reflect.Struct:
+		var enc anyEncoder
+		building[t] = &enc
+		enc = newAnyStructEncoder(t, building)
+		delete(building, t)
+		return enc
+	}
+
+	// chan, func, complex and unsafe.Pointer
+	return nil
+}
+
+// newAnyMarshalerEncoder returns the encoder of t if it implements ObjectMarshaler,
+// json.Marshaler or encoding.TextMarshaler, by value or by pointer.
+func newAnyMarshalerEncoder(t reflect.Type) anyEncoder {
+	var value func(p unsafe.Pointer) any
+	switch {
+	case t.Kind() == reflect.Ptr && (t.Implements(anyObjectMarshalerType) || t.Implements(anyJSONMarshalerType) || t.Implements(anyTextMarshalerType)):
+		value = func(p unsafe.Pointer) any {
+			if *(*unsafe.Pointer)(p) == nil {
+				return nil
+			}
+			return reflect.NewAt(t, p).Elem().Interface()
+		}
+	case t.Kind() != reflect.Ptr && (reflect.PtrTo(t).Implements(anyObjectMarshalerType) || reflect.PtrTo(t).Implements(anyJSONMarshalerType) || reflect.PtrTo(t).Implements(anyTextMarshalerType)):
+		value = func(p unsafe.Pointer) any {
+			return reflect.NewAt(t, p).Interface()
+		}
+	default:
+		return nil
+	}
+
+	return func(e *Entry, p unsafe.Pointer) {
+		switch v := value(p).(type) {
+		case nil:
+			e.buf = append(e.buf, "null"...)
+		case ObjectMarshaler:
+			n := len(e.buf)
+			e.Object("", v)
+			e.stripKey(n)
+		case json.Marshaler:
+			b, err := v.MarshalJSON()
+			if err != nil {
+				e.buf = append(e.buf, `"marshaling error: `...)
+				e.string(err.Error())
+				e.buf = append(e.buf, '"')
+			} else {
+				e.buf = append(e.buf, b...)
+			}
+		case encoding.TextMarshaler:
+			b, err := v.MarshalText()
+			if err != nil {
+				e.buf = append(e.buf, `"marshaling error: `...)
+				e.string(err.Error())
+				e.buf = append(e.buf, '"')
+			} else {
+				e.buf = append(e.buf, '"')
+				e.bytes(b)
+				e.buf = append(e.buf, '"')
+			}
+		}
+	}
+}
+
+type anySlice struct {
+	Data unsafe.Pointer
+	Len  int
+	Cap  int
+}
+
+// newAnyMapEncoder returns the encoder of map type t, the keys are resolved and sorted as encoding/json.
+func newAnyMapEncoder(t reflect.Type, building map[reflect.Type]*anyEncoder) anyEncoder {
+	var key func(k reflect.Value) string
+	switch t.Key().Kind() {
+	case reflect.String:
+		key = func(k reflect.Value) string { return k.String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key = func(k reflect.Value) string { return strconv.FormatInt(k.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		key = func(k reflect.Value) string { return strconv.FormatUint(k.Uint(), 10) }
+	}
+	if t.Key().Kind() != reflect.String && t.Key().Implements(anyTextMarshalerType) {
+		key = func(k reflect.Value) string {
+			if k.Kind() == reflect.Ptr && k.IsNil() {
+				return ""
+			}
+			b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return "marshaling error: " + err.Error()
+			}
+			return string(b)
+		}
+	}
+	if key == nil {
+		return nil
+	}
+	elem := newAnyEncoder(t.Elem(), building)
+	if elem == nil {
+		return nil
+	}
+	iface := t.Elem().Kind() == reflect.Interface && t.Elem().NumMethod() == 0
+
+	return func(e *Entry, p unsafe.Pointer) {
+		m := reflect.NewAt(t, p).Elem()
+		if m.IsNil() || !e.enter(m.UnsafePointer()) {
+			e.buf = append(e.buf, "null"...)
+			return
+		}
+		type kv struct {
+			key string
+			val reflect.Value
+		}
+		kvs := make([]kv, 0, m.Len())
+		for it := m.MapRange(); it.Next(); {
+			kvs = append(kvs, kv{key(it.Key()), it.Value()})
+		}
+		sort.Slice(kvs, func(i, j int) bool { return kvs[i].key < kvs[j].key })
+
+		var tmp reflect.Value
+		e.buf = append(e.buf, '{')
+		for i, kv := range kvs {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = append(e.buf, '"')
+			e.string(kv.key)
+			e.buf = append(e.buf, '"', ':')
+			if iface {
+				e.anyValue(kv.val.Interface())
+				continue
+			}
+			if !tmp.IsValid() {
+				tmp = reflect.New(t.Elem()).Elem()
+			}
+			tmp.Set(kv.val)
+			elem(e, unsafe.Pointer(tmp.UnsafeAddr()))
+		}
+		e.buf = append(e.buf, '}')
+		e.leave()
+	}
+}
+
+type anyField struct {
+	name      string
+	key       []byte    // `,"name":`
+	ptrs      []uintptr // the offsets of embedded pointers to follow
+	offset    uintptr
+	depth     int
+	tagged    bool
+	omitempty bool
+	quoted    bool
+	requote   bool // quotes the encoded string again for the string option
+	indirect  bool // quotes the element of pointer for the string option
+	redact    bool
+	empty     func(p unsafe.Pointer) bool
+	enc       anyEncoder
+}
+
+// newAnyStructEncoder returns the encoder of struct type t.
+func newAnyStructEncoder(t reflect.Type, building map[reflect.Type]*anyEncoder) anyEncoder {
+	fields := anyStructFields(t, 0, nil, 0, building)
+
+	// the fields of the same name follow the rules of encoding/json, the shallowest
+	// field wins, or the only tagged field of the shallowest ones, otherwise none.
+	type candidate struct{ depth, index, count, tagged, taggedIndex int }
+	names := make(map[string]*candidate)
+	for i, f := range fields {
+		c := names[f.name]
+		if c == nil || f.depth < c.depth {
+			c = &candidate{depth: f.depth, index: i}
+			names[f.name] = c
+		} else if f.depth > c.depth {
+			continue
+		}
+		c.count++
+		if f.tagged {
+			c.tagged++
+			c.taggedIndex = i
+		}
+	}
+	var visible []anyField
+	for i, f := range fields {
+		if c := names[f.name]; (c.count == 1 && c.index == i) || (c.count > 1 && c.tagged == 1 && c.taggedIndex == i) {
+			visible = append(visible, f)
+		}
+	}
+	fields = visible
+
+	return func(e *Entry, p unsafe.Pointer) {
+		n := len(e.buf)
+		for i := range fields {
+			f := &fields[i]
+			fp := p
+			for _, offset := range f.ptrs {
+				if fp = *(*unsafe.Pointer)(unsafe.Pointer(uintptr(fp) + offset)); fp == nil {
+					break
+				}
+			}
+			if fp == nil {
+				continue
+			}
+			fp = unsafe.Pointer(uintptr(fp) + f.offset)
+			if f.omitempty && f.empty != nil && f.empty(fp) {
+				continue
+			}
+			e.buf = append(e.buf, f.key...)
+			switch {
+			case f.redact:
+				r, i := e.tagRedactor(), len(e.buf)
+				if r.Hash {
+					f.enc(e, fp)
+				}
+				e.buf = r.redactValue(e.buf, i)
+			case f.indirect && *(*unsafe.Pointer)(fp) == nil:
+				f.enc(e, fp)
+			case f.quoted:
+				e.buf = append(e.buf, '"')
+				f.enc(e, fp)
+				e.buf = append(e.buf, '"')
+			case f.requote:
+				i := len(e.buf)
+				f.enc(e, fp)
+				b := bbpool.Get().(*bb)
+				b.B = append(b.B[:0], e.buf[i:]...)
+				e.buf = append(e.buf[:i], '"')
+				e.escapeb(b.B)
+				e.buf = append(e.buf, '"')
+				if cap(b.B) <= bbcap {
+					bbpool.Put(b)
+				}
+			default:
+				f.enc(e, fp)
+			}
+		}
+		if n < len(e.buf) {
+			e.buf[n] = '{'
+			e.buf = append(e.buf, '}')
+		} else {
+			e.buf = append(e.buf, '{', '}')
+		}
+	}
+}
+
+// anyStructFields returns the fields of struct type t and its embedded structs.
+func anyStructFields(t reflect.Type, offset uintptr, ptrs []uintptr, depth int, building map[reflect.Type]*anyEncoder) []anyField {
+	var fields []anyField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ltag := sf.Tag.Get("log")
+		if ltag == "-" {
+			continue
+		}
+		_, lopts, _ := strings.Cut(ltag, ",")
+
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			et := ft
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && !anyKnownType(et) {
+				// promote the fields of embedded struct
+				var sub []anyField
+				if ft.Kind() == reflect.Ptr {
+					sub = anyStructFields(et, 0, append(append([]uintptr(nil), ptrs...), offset+sf.Offset), depth+1, building)
+				} else {
+					sub = anyStructFields(et, offset+sf.Offset, ptrs, depth+1, building)
+				}
+				fields = append(fields, sub...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+
+		f := anyField{
+			name:   name,
+			tagged: tagged,
+			ptrs:   ptrs,
+			offset: offset + sf.Offset,
+			depth:  depth,
+			redact: anyTagOption(lopts, "redact"),
+		}
+		key, _ := json.Marshal(name)
+		f.key = append(append([]byte{','}, key...), ':')
+		f.omitempty = anyTagOption(opts, "omitempty")
+		f.empty = anyEmpty(ft)
+		if qt := ft; anyTagOption(opts, "string") {
+			if qt.Name() == "" && qt.Kind() == reflect.Ptr {
+				qt, f.indirect = qt.Elem(), true
+			}
+			if anyMarshalerType(ft) || anyMarshalerType(qt) {
+				qt = nil
+			}
+			switch anyKind(qt) {
+			case reflect.String:
+				f.quoted = qt == anyNumberType
+				f.requote = !f.quoted
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64:
+				f.quoted = true
+			}
+		}
+		if f.enc = newAnyEncoder(ft, building); f.enc == nil {
+			f.enc = newAnyFallbackEncoder(ft)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// newAnyFallbackEncoder returns the encoder of unsupported type t, which writes
+// the values by encoding/json.
+func newAnyFallbackEncoder(t reflect.Type) anyEncoder {
+	return func(e *Entry, p unsafe.Pointer) {
+		n := len(e.buf)
+		e.Interface("", reflect.NewAt(t, p).Elem().Interface())
+		e.stripKey(n)
+	}
+}
+
+func anyKnownType(t reflect.Type) bool {
+	switch t {
+	case anyTimeType, anyIPNetType, anyNetIPAddrType, anyNetIPAddrPortType, anyNetIPPrefixType:
+		return true
+	}
+	return reflect.PtrTo(t).Implements(anyObjectMarshalerType) ||
+		reflect.PtrTo(t).Implements(anyJSONMarshalerType) ||
+		reflect.PtrTo(t).Implements(anyTextMarshalerType)
+}
+
+// anyMarshalerType reports whether t encodes itself by MarshalJSON or MarshalText,
+// which ignores the string option as encoding/json.
+func anyMarshalerType(t reflect.Type) bool {
+	return t.Implements(anyJSONMarshalerType) || t.Implements(anyTextMarshalerType) ||
+		reflect.PtrTo(t).Implements(anyJSONMarshalerType) || reflect.PtrTo(t).Implements(anyTextMarshalerType)
+}
+
+// anyKind returns the kind of t, or reflect.Invalid if t is nil.
+func anyKind(t reflect.Type) reflect.Kind {
+	if t == nil {
+		return reflect.Invalid
+	}
+	return t.Kind()
+}
+
+func anyTagOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// anyEmpty returns the function reports whether the value of t is empty for omitempty.
+func anyEmpty(t reflect.Type) func(p unsafe.Pointer) bool {
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(p unsafe.Pointer) bool { return !*(*bool)(p) }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(p unsafe.Pointer) bool { return reflect.NewAt(t, p).Elem().Int() == 0 }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(p unsafe.Pointer) bool { return reflect.NewAt(t, p).Elem().Uint() == 0 }
+	case reflect.Float32, reflect.Float64:
+		return func(p unsafe.Pointer) bool { return reflect.NewAt(t, p).Elem().Float() == 0 }
+	case reflect.String:
+		return func(p unsafe.Pointer) bool { return len(*(*string)(p)) == 0 }
+	case reflect.Slice:
+		return func(p unsafe.Pointer) bool { return (*anySlice)(p).Len == 0 }
+	case reflect.Map:
+		return func(p unsafe.Pointer) bool { return reflect.NewAt(t, p).Elem().Len() == 0 }
+	case reflect.Array:
+		return func(p unsafe.Pointer) bool { return t.Len() == 0 }
+	case reflect.Ptr:
+		return func(p unsafe.Pointer) bool { return *(*unsafe.Pointer)(p) == nil }
+	case reflect.Interface:
+		return func(p unsafe.Pointer) bool { return (*[2]unsafe.Pointer)(p)[0] == nil }
+	}
+	return nil
+}
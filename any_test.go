@@ -0,0 +1,338 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type anyTestBase struct {
+	ID      int    `json:"id"`
+	Version string `json:"version,omitempty"`
+}
+
+type anyTestHeader struct {
+	Name  string
+	Value string
+}
+
+type anyTestText string
+
+func (s anyTestText) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(s))), nil
+}
+
+type anyTestRequest struct {
+	anyTestBase
+	*anyTestHeader
+	Method   string            `json:"method"`
+	Path     string            `json:"path,omitempty"`
+	Query    map[string]string `json:"query"`
+	Headers  []anyTestHeader   `json:"headers"`
+	Body     []byte            `json:"body"`
+	Size     int64             `json:"size,string"`
+	Ratio    float32           `json:"ratio"`
+	Tags     [2]string         `json:"tags"`
+	Extra    any               `json:"extra"`
+	Text     anyTestText       `json:"text"`
+	Next     *anyTestRequest   `json:"next,omitempty"`
+	Ignored  string            `json:"-"`
+	Password string            `json:"password" log:",redact"`
+	Secret   string            `log:"-"`
+	private  string
+}
+
+func TestAnyStructLikeJSON(t *testing.T) {
+	req := &anyTestRequest{
+		anyTestBase:   anyTestBase{ID: 1},
+		anyTestHeader: &anyTestHeader{Name: "promoted", Value: "v"},
+		Method:        "GET",
+		Query:         map[string]string{"b": "2", "a": "1 \"quoted\""},
+		Headers:       []anyTestHeader{{"Accept", "*/*"}},
+		Body:          []byte("hello"),
+		Size:          42,
+		Ratio:         0.5,
+		Tags:          [2]string{"x", "y"},
+		Extra:         map[string]any{"n": 1.5, "list": []any{true, nil, "s"}},
+		Text:          "text",
+		Next:          &anyTestRequest{Method: "POST", Query: map[string]string{}},
+		Ignored:       "ignored",
+		private:       "private",
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Any("req", req).Msg("")
+
+	var got struct {
+		Req map[string]any `json:"req"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json unmarshal error: %+v, %s", err, buf.Bytes())
+	}
+
+	b, _ := json.Marshal(req)
+	var want map[string]any
+	_ = json.Unmarshal(b, &want)
+	want["password"] = "[REDACTED]"
+	delete(want, "Secret")
+	delete(want["next"].(map[string]any), "Secret")
+	want["next"].(map[string]any)["password"] = "[REDACTED]"
+
+	if !reflect.DeepEqual(got.Req, want) {
+		t.Errorf("any struct mismatch\nwant: %s\ngot:  %s", b, buf.Bytes())
+	}
+	if strings.Contains(buf.String(), "Secret") {
+		t.Errorf("any struct should omit the log:\"-\" field: %s", buf.Bytes())
+	}
+}
+
+type anyTestKnown struct {
+	Time     time.Time
+	Dur      time.Duration
+	IP       net.IP
+	Addr     netip.Addr
+	AddrPort netip.AddrPort
+	Object   *cborTestObject
+	Objects  []cborTestObject
+	Err      error
+	Raw      json.RawMessage
+	Nil      *anyTestKnown
+}
+
+func TestAnyStructKnownTypes(t *testing.T) {
+	v := anyTestKnown{
+		Time:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Dur:      1500 * time.Millisecond,
+		IP:       net.IP{192, 168, 0, 1},
+		Addr:     netip.MustParseAddr("2001:db8::1"),
+		AddrPort: netip.MustParseAddrPort("1.1.1.1:53"),
+		Object:   &cborTestObject{"jack", 18},
+		Objects:  []cborTestObject{{"rose", 17}},
+		Err:      errors.New("an error"),
+		Raw:      json.RawMessage(`{"k":[1]}`),
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Any("v", v).Msg("")
+
+	want := `"v":{"Time":"2020-01-02T03:04:05Z","Dur":1500,"IP":"192.168.0.1","Addr":"2001:db8::1","AddrPort":"1.1.1.1:53","Object":{"name":"jack","age":18},"Objects":[{"name":"rose","age":17}],"Err":{},"Raw":{"k":[1]},"Nil":null}`
+	if s := buf.String(); !strings.Contains(s, want) || !json.Valid(buf.Bytes()) {
+		t.Errorf("any struct should use the entry methods of known types\nwant: %s\ngot:  %s", want, s)
+	}
+}
+
+type anyTestNode struct {
+	Name     string
+	Children []*anyTestNode
+	Parent   *anyTestNode
+}
+
+func TestAnyStructCycle(t *testing.T) {
+	root := &anyTestNode{Name: "root"}
+	root.Children = []*anyTestNode{{Name: "child", Parent: root}}
+	root.Parent = root
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Any("node", root).Msg("")
+
+	if s := buf.String(); !strings.Contains(s, `"node":{"Name":"root","Children":[{"Name":"child","Children":null,"Parent":null}],"Parent":null}`) {
+		t.Errorf("any cyclic struct should end with null: %s", s)
+	}
+}
+
+func TestAnyUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Interface("fn", func() {}).Any("st", struct {
+		C chan int
+		N int
+	}{}).Msg("")
+
+	if s := buf.String(); !strings.Contains(s, `"fn":"marshaling error: json: unsupported type: func()"`) ||
+		!strings.Contains(s, `"st":{"C":"marshaling error: json: unsupported type: chan int","N":0}`) {
+		t.Errorf("any unsupported types should fall back to encoding/json: %s", s)
+	}
+}
+
+type anyTestTextKey struct{ A, B int }
+
+func (k anyTestTextKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d-%d", k.A, k.B)), nil
+}
+
+func TestAnyStructFallbackRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Any("text", struct {
+		D map[anyTestTextKey]int
+		Z string `log:",redact"`
+	}{D: map[anyTestTextKey]int{{2, 1}: 2, {1, 2}: 1}, Z: "secret"}).Any("chan", struct {
+		C chan int
+		M map[[2]int]int
+		Z string `log:",redact"`
+	}{M: map[[2]int]int{{1, 2}: 3}, Z: "secret"}).Msg("")
+
+	s := buf.String()
+	if strings.Contains(s, "secret") {
+		t.Errorf("any struct with unsupported fields should be redacted: %s", s)
+	}
+	if !strings.Contains(s, `"text":{"D":{"1-2":1,"2-1":2},"Z":"[REDACTED]"}`) {
+		t.Errorf("any map with text marshaler keys should be encoded as json: %s", s)
+	}
+	if !strings.Contains(s, `"chan":{"C":"marshaling error: json: unsupported type: chan int","M":"marshaling error: json: unsupported `) || !strings.Contains(s, `,"Z":"[REDACTED]"}`) {
+		t.Errorf("any unsupported fields should fall back one by one: %s", s)
+	}
+}
+
+func TestAnyJSONNumber(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Any("n", struct {
+		A json.Number
+		B json.Number
+		C json.Number `json:",string"`
+		D json.Number
+	}{A: "1", B: "-1.5e3", C: "2", D: "abc"}).Msg("")
+
+	want := `"n":{"A":1,"B":-1.5e3,"C":"2","D":"marshaling error: json: invalid number literal \"abc\""}`
+	if s := buf.String(); !strings.Contains(s, want) {
+		t.Errorf("any json.Number should be encoded as number\nwant: %s\ngot:  %s", want, s)
+	}
+}
+
+type anyTestTagged struct {
+	Name string `json:"Name"`
+}
+
+type anyTestUntagged struct {
+	Name string
+	ID   int
+}
+
+type anyTestOther struct {
+	Name string `json:"Name"`
+	ID   int    `json:"ID"`
+}
+
+type anyTestDeep struct {
+	anyTestUntagged
+}
+
+// anyTestStructOf returns a struct which embeds the values, it is built at runtime
+// because vet rejects the repeated json tags of embedded fields.
+func anyTestStructOf(values ...any) any {
+	var fields []reflect.StructField
+	for i, v := range values {
+		fields = append(fields, reflect.StructField{Name: fmt.Sprintf("Embedded%d", i), Type: reflect.TypeOf(v), Anonymous: true})
+	}
+	v := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, value := range values {
+		v.Field(i).Set(reflect.ValueOf(value))
+	}
+	return v.Interface()
+}
+
+func TestAnyStructMatchJSON(t *testing.T) {
+	cases := []any{
+		// a tagged field beats an untagged field at the same depth
+		struct {
+			anyTestUntagged
+			anyTestTagged
+		}{anyTestUntagged{"untagged", 1}, anyTestTagged{"tagged"}},
+		// untagged or tagged fields of the same name at the same depth are dropped
+		struct {
+			anyTestUntagged
+			anyTestHeader
+		}{anyTestUntagged{"a", 1}, anyTestHeader{"b", "c"}},
+		anyTestStructOf(anyTestUntagged{"a", 1}, anyTestOther{"b", 2}, anyTestTagged{"c"}),
+		// a shallower field beats the deeper ones whatever their tags
+		struct {
+			anyTestTagged
+			Name string
+		}{anyTestTagged{"deep"}, "shallow"},
+		struct {
+			anyTestUntagged
+			anyTestDeep
+		}{anyTestUntagged{"a", 1}, anyTestDeep{anyTestUntagged{"b", 2}}},
+		struct {
+			anyTestDeep
+			anyTestTagged
+		}{anyTestDeep{anyTestUntagged{"deep", 1}}, anyTestTagged{"tagged"}},
+		// the string option
+		struct {
+			S  string          `json:"s,string"`
+			E  string          `json:",string"`
+			Q  string          `json:"q,string"`
+			I  int             `json:"i,string"`
+			U  uint8           `json:"u,string"`
+			F  float64         `json:"f,string"`
+			B  bool            `json:"b,string"`
+			N  json.Number     `json:"n,string"`
+			T  anyTestText     `json:"t,string"`
+			P  *int            `json:"p,string"`
+			PN *int            `json:"pn,string"`
+			PS *string         `json:"ps,string"`
+			SS []string        `json:"ss,string"`
+			M  map[string]bool `json:"m,string"`
+		}{S: "abc", Q: "a \"quoted\"\n\\", I: -1, U: 2, F: 1.5, B: true, N: "42", T: "text", P: new(int), PS: new(string), SS: []string{"x"}, M: map[string]bool{"k": true}},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		logger := Logger{Writer: IOWriter{&buf}}
+		logger.Info().Any("v", c).Msg("")
+
+		b, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("json marshal error: %+v", err)
+		}
+		if want := `,"v":` + string(b) + `}`; !strings.Contains(buf.String(), want) {
+			t.Errorf("any struct should be encoded as encoding/json\nwant: %s\ngot:  %s", want, buf.Bytes())
+		}
+	}
+}
+
+func TestAnyRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Redactor: &Redactor{Keys: []string{"req.query.a"}},
+		Writer:   IOWriter{&buf},
+	}
+	logger.Info().Any("req", anyTestRequest{Query: map[string]string{"a": "secret"}}).Msg("")
+
+	if s := buf.String(); strings.Contains(s, "secret") || !strings.Contains(s, `"query":{"a":"[REDACTED]"}`) {
+		t.Errorf("any struct should be redacted: %s", s)
+	}
+}
+
+func BenchmarkAnyStruct(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Writer:     IOWriter{io.Discard},
+	}
+	req := &anyTestRequest{
+		Method:  "GET",
+		Path:    "/api/v1/users",
+		Headers: []anyTestHeader{{"Accept", "*/*"}, {"User-Agent", "curl/8.0"}},
+		Size:    1024,
+		Ratio:   0.5,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Any("req", req).Msg("hello world")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -26,9 +27,13 @@ type AsyncWriter struct {
 	ch      chan *Entry
 	chClose chan error
 	file    *FileWriter
+	pending int32
+	mu      sync.Mutex
+	cond    sync.Cond
 }
 
 func (w *AsyncWriter) init() {
+	w.cond.L = &w.mu
 	w.ch = make(chan *Entry, w.ChannelSize)
 	w.chClose = make(chan error)
 	w.file, _ = w.Writer.(*FileWriter)
@@ -37,11 +42,13 @@ func (w *AsyncWriter) init() {
 	} else {
 		go w.writer()
 	}
+	RegisterFlusher(w)
 }
 
 // Close implements io.Closer, and closes the underlying Writer.
 func (w *AsyncWriter) Close() (err error) {
 	w.once.Do(w.init)
+	UnregisterFlusher(w)
 	close(w.ch)
 	err = <-w.chClose
 	if closer, ok := w.Writer.(io.Closer); ok {
@@ -52,6 +59,21 @@ func (w *AsyncWriter) Close() (err error) {
 	return
 }
 
+// Flush implements Flusher, and waits until the queued entries are written.
+func (w *AsyncWriter) Flush() (err error) {
+	if atomic.LoadInt32(&w.pending) > 0 {
+		w.mu.Lock()
+		for atomic.LoadInt32(&w.pending) > 0 {
+			w.cond.Wait()
+		}
+		w.mu.Unlock()
+	}
+	if flusher, ok := w.Writer.(Flusher); ok {
+		err = flusher.Flush()
+	}
+	return
+}
+
 var ErrAsyncWriterFull = errors.New("async writer is full")
 
 var eepool = sync.Pool{
@@ -79,16 +101,22 @@ func (w *AsyncWriter) WriteEntry(e *Entry) (int, error) {
 	// cheating to logger pool
 	entry := epool.Get().(*Entry)
 	entry.Level = e.Level
+	entry.names = e.names
+	entry.enc = e.enc
+	entry.errhandler = e.errhandler
 	entry.buf, e.buf = e.buf, entry.buf
 
 	// snapshot length before sending, entry is owned by the writer goroutine afterwards
 	n := len(entry.buf)
 
+	atomic.AddInt32(&w.pending, 1)
 	if w.DiscardOnFull {
 		select {
 		case w.ch <- entry:
 			return n, nil
 		default:
+			w.done(1)
+			entry.buf, e.buf = e.buf, entry.buf
 			if cap(entry.buf) <= bbcap {
 				epool.Put(entry)
 			}
@@ -107,10 +135,24 @@ func (w *AsyncWriter) writer() {
 			break
 		}
 		_, err = w.Writer.WriteEntry(entry)
+		if err != nil {
+			entry.handleError(err)
+		}
 		epool.Put(entry)
+		w.done(1)
 	}
 	w.chClose <- err
 }
 
+// done marks n queued entries as written, and wakes up the waiting Flush calls
+// if there is no queued entry.
+func (w *AsyncWriter) done(n int32) {
+	if atomic.AddInt32(&w.pending, -n) == 0 {
+		w.mu.Lock()
+		w.cond.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
 var _ Writer = (*AsyncWriter)(nil)
 var _ io.Writer = (*AsyncWriter)(nil)
@@ -13,13 +13,14 @@ func (w *AsyncWriter) writever() {
 	var es [IOV_MAX]*Entry
 	var iovs [IOV_MAX]syscall.Iovec
 	var err error
-	var quit bool
+	var quit, sync bool
 	for !quit {
 		// wait an item from channel
 		es[0] = <-w.ch
 		if es[0] == nil {
 			break
 		}
+		sync = w.file.synclevel(es[0].Level)
 		iovs[0].Base = &es[0].buf[0]
 		iovs[0].SetLen(len(es[0].buf))
 		// drain the channel
@@ -34,19 +35,28 @@ func (w *AsyncWriter) writever() {
 				quit = true
 				break
 			}
+			sync = sync || w.file.synclevel(es[n].Level)
 			iovs[n].Base = &es[n].buf[0]
 			iovs[n].SetLen(len(es[n].buf))
 			n++
 		}
 		// writev
 		_, err = w.file.WriteV(iovs[:n])
+		// fsync the batch containing the entries at or above SyncLevel
+		if err == nil && sync {
+			err = w.file.Sync()
+		}
 		// quit = err != nil
 		// return entries to pool
 		for i := 0; i < n; i++ {
+			if err != nil {
+				es[i].handleError(err)
+			}
 			epool.Put(es[i])
 			es[i] = nil
 			iovs[i].Base = nil
 		}
+		w.done(int32(n))
 	}
 	w.chClose <- err
 }
@@ -3,7 +3,10 @@ package log
 import (
 	"io"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestAsyncWriterZero(t *testing.T) {
@@ -32,6 +35,49 @@ func TestAsyncWriterSmall(t *testing.T) {
 	}
 }
 
+type asyncSlowWriter struct {
+	n int32
+}
+
+func (w *asyncSlowWriter) WriteEntry(e *Entry) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(&w.n, 1)
+	return len(e.buf), nil
+}
+
+func TestAsyncWriterFlush(t *testing.T) {
+	slow := &asyncSlowWriter{}
+	w := &AsyncWriter{
+		ChannelSize: 16,
+		Writer:      slow,
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = wlprintf(w, InfoLevel, "%d during async writer flush\n", i)
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("async flush error: %+v", err)
+	}
+	if n := atomic.LoadInt32(&slow.n); n != 5 {
+		t.Errorf("async flush should wait the queued entries: %d != 5", n)
+	}
+
+	// discarded entries must not block flush.
+	w.DiscardOnFull = true
+	for i := 0; i < 100; i++ {
+		_, _ = wlprintf(w, InfoLevel, "%d during async writer flush\n", i)
+	}
+	done := make(chan error)
+	go func() { done <- w.Flush() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("async flush should not block after discarding entries")
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("async close error: %+v", err)
+	}
+}
+
 func TestAsyncWriterSize(t *testing.T) {
 	writer1 := &FileWriter{
 		Filename: "async_file_test1.log",
@@ -80,6 +126,108 @@ func TestAsyncWriterSize(t *testing.T) {
 	}
 }
 
+func TestAsyncWriterFileRotateInterval(t *testing.T) {
+	file := &FileWriter{
+		Filename:       filepath.Join(t.TempDir(), "async_file_interval.log"),
+		TimeFormat:     TimeFormatUnixMs,
+		MaxBackups:     10,
+		RotateInterval: time.Hour,
+	}
+	w := &AsyncWriter{
+		ChannelSize: 16,
+		Writer:      file,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+	file.mu.Lock()
+	first := file.file.Name()
+	file.rotateat = 0
+	file.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+	if _, err := w.Write([]byte("b\n")); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+
+	file.mu.Lock()
+	second := file.file.Name()
+	file.mu.Unlock()
+	if second == first {
+		t.Fatalf("async file writer must rotate after the interval boundary: %s", first)
+	}
+	if data, _ := os.ReadFile(second); string(data) != "b\n" {
+		t.Fatalf("async file writer must write to the rotated file: %q", data)
+	}
+}
+
+func TestAsyncWriterFileRename(t *testing.T) {
+	file := &FileWriter{
+		Filename: filepath.Join(t.TempDir(), "async_file_rename.log"),
+		Rename:   true,
+	}
+	w := &AsyncWriter{
+		ChannelSize: 16,
+		Writer:      file,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+	if err := os.Rename(file.Filename, file.Filename+".1"); err != nil {
+		t.Fatal(err)
+	}
+	file.mu.Lock()
+	file.checkat = 0
+	file.mu.Unlock()
+	if _, err := w.Write([]byte("b\n")); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+
+	if data, _ := os.ReadFile(file.Filename); string(data) != "b\n" {
+		t.Fatalf("async file writer must reopen the renamed file: %q", data)
+	}
+}
+
+func TestAsyncWriterFileSyncLevel(t *testing.T) {
+	file := &FileWriter{
+		Filename:  filepath.Join(t.TempDir(), "async_file_sync.log"),
+		SyncLevel: ErrorLevel,
+	}
+	w := &AsyncWriter{
+		ChannelSize: 16,
+		Writer:      file,
+	}
+	defer w.Close()
+
+	dirty := func() bool {
+		file.mu.Lock()
+		defer file.mu.Unlock()
+		return file.dirty
+	}
+
+	if _, err := wlprintf(w, WarnLevel, "warn\n"); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+	if !dirty() {
+		t.Fatalf("async file writer must not sync the entries below SyncLevel")
+	}
+	if _, err := wlprintf(w, ErrorLevel, "error\n"); err != nil {
+		t.Fatalf("async writer error: %+v", err)
+	}
+	_ = w.Flush()
+	if data, _ := os.ReadFile(file.Filename); string(data) != "warn\nerror\n" || dirty() {
+		t.Fatalf("async file writer must sync the entries at SyncLevel: %q", data)
+	}
+}
+
 func BenchmarkSyncFileWriter(b *testing.B) {
 	logger := Logger{
 		Writer: &FileWriter{
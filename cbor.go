@@ -0,0 +1,506 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CBOR (RFC 8949) major types and simple values used by CBOREncoding.
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborFalse   = cborSimple | 20
+	cborTrue    = cborSimple | 21
+	cborNull    = cborSimple | 22
+	cborFloat32 = cborSimple | 26
+	cborFloat64 = cborSimple | 27
+	cborBreak   = cborSimple | 31
+
+	cborIndefinite = 31
+)
+
+var errInvalidCBOR = errors.New("invalid cbor data")
+
+// cborAppendHead appends the head of CBOR data item with major type and argument n.
+func cborAppendHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(dst, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(dst, major|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func cborAppendString(dst []byte, s string) []byte {
+	dst = cborAppendHead(dst, cborText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func cborAppendText(dst []byte, b []byte) []byte {
+	dst = cborAppendHead(dst, cborText, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func cborAppendBytes(dst []byte, b []byte) []byte {
+	dst = cborAppendHead(dst, cborBytes, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func cborAppendInt(dst []byte, i int64) []byte {
+	if i < 0 {
+		return cborAppendHead(dst, cborNegint, uint64(-1-i))
+	}
+	return cborAppendHead(dst, cborUint, uint64(i))
+}
+
+func cborAppendUint(dst []byte, i uint64) []byte {
+	return cborAppendHead(dst, cborUint, i)
+}
+
+func cborAppendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, cborTrue)
+	}
+	return append(dst, cborFalse)
+}
+
+// cborAppendFloat appends f as a single-precision float if it is lossless, otherwise as a double.
+func cborAppendFloat(dst []byte, f float64) []byte {
+	if f32 := float32(f); float64(f32) == f || f != f {
+		n := math.Float32bits(f32)
+		return append(dst, cborFloat32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	n := math.Float64bits(f)
+	return append(dst, cborFloat64, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// cborAppendJSONFields transcodes the JSON object members of src to dst, a leading '{'
+// starts a map of indefinite length and a '}' ends it.
+func cborAppendJSONFields(dst, src []byte) []byte {
+	for len(src) != 0 {
+		switch src[0] {
+		case ',', ':', ' ', '\t', '\r', '\n':
+			src = src[1:]
+		case '{':
+			dst = append(dst, cborMap|cborIndefinite)
+			src = src[1:]
+		case '}':
+			dst = append(dst, cborBreak)
+			src = src[1:]
+		default:
+			// key
+			dst, src = cborAppendJSON(dst, src)
+			for len(src) != 0 && src[0] != ':' {
+				src = src[1:]
+			}
+			if len(src) == 0 {
+				return append(dst, cborNull)
+			}
+			// value
+			src = src[1:]
+			for len(src) != 0 && (src[0] == ' ' || src[0] == '\t' || src[0] == '\r' || src[0] == '\n') {
+				src = src[1:]
+			}
+			if len(src) == 0 {
+				return append(dst, cborNull)
+			}
+			dst, src = cborAppendJSON(dst, src)
+		}
+	}
+	return dst
+}
+
+// cborAppendJSON transcodes the JSON value at the beginning of src to dst, and returns the rest of src.
+func cborAppendJSON(dst, src []byte) ([]byte, []byte) {
+	switch src[0] {
+	case '"':
+		n := jsonValueLen(src)
+		if n < 2 {
+			return cborAppendText(dst, src[1:]), nil
+		}
+		s := src[1 : n-1]
+		for _, c := range s {
+			if c == '\\' {
+				return cborAppendJSONString(dst, s), src[n:]
+			}
+		}
+		return cborAppendText(dst, s), src[n:]
+	case '{':
+		dst = append(dst, cborMap|cborIndefinite)
+		src = src[1:]
+		for len(src) != 0 && src[0] != '}' {
+			switch src[0] {
+			case ',', ':', ' ', '\t', '\r', '\n':
+				src = src[1:]
+			default:
+				dst, src = cborAppendJSON(dst, src)
+			}
+		}
+		if len(src) != 0 {
+			src = src[1:]
+		}
+		return append(dst, cborBreak), src
+	case '[':
+		dst = append(dst, cborArray|cborIndefinite)
+		src = src[1:]
+		for len(src) != 0 && src[0] != ']' {
+			switch src[0] {
+			case ',', ' ', '\t', '\r', '\n':
+				src = src[1:]
+			default:
+				dst, src = cborAppendJSON(dst, src)
+			}
+		}
+		if len(src) != 0 {
+			src = src[1:]
+		}
+		return append(dst, cborBreak), src
+	}
+
+	n := jsonValueLen(src)
+	if n == 0 {
+		n = 1
+	}
+	s := b2s(src[:n])
+	switch s {
+	case "true":
+		return append(dst, cborTrue), src[n:]
+	case "false":
+		return append(dst, cborFalse), src[n:]
+	case "null":
+		return append(dst, cborNull), src[n:]
+	}
+	if digits := strings.TrimPrefix(s, "-"); 0 < len(digits) && len(digits) <= 19 {
+		var u uint64
+		for _, c := range []byte(digits) {
+			if c < '0' || c > '9' {
+				goto float
+			}
+			u = u*10 + uint64(c-'0')
+		}
+		if len(digits) == len(s) || u == 0 {
+			return cborAppendUint(dst, u), src[n:]
+		}
+		return cborAppendHead(dst, cborNegint, u-1), src[n:]
+	}
+float:
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return cborAppendFloat(dst, f), src[n:]
+	}
+	return cborAppendText(dst, src[:n]), src[n:]
+}
+
+// cborAppendJSONString appends the unescaped content of JSON string s as a text string.
+func cborAppendJSONString(dst, s []byte) []byte {
+	// reserve the longest head of s, the unescaped string is not longer than s.
+	i := len(dst)
+	dst = cborAppendHead(dst, cborText, uint64(len(s)))
+	m := len(dst) - i
+	dst = jsonUnescape(s, dst)
+	var tmp [9]byte
+	head := cborAppendHead(tmp[:0], cborText, uint64(len(dst)-i-m))
+	copy(dst[i:], head)
+	if len(head) < m {
+		dst = append(dst[:i+len(head)], dst[i+m:]...)
+	}
+	return dst
+}
+
+// CBORToJSON appends the JSON of the first CBOR data item in src to dst, and returns
+// the number of bytes read from src. It returns io.ErrUnexpectedEOF if the item is incomplete.
+// The byte strings are converted to base64 strings and the tags are omitted.
+func CBORToJSON(dst, src []byte) ([]byte, int, error) {
+	return cborToJSON(dst, src, 0)
+}
+
+func cborToJSON(dst, src []byte, depth int) ([]byte, int, error) {
+	if depth > 1000 {
+		return dst, 0, errInvalidCBOR
+	}
+	major, info, n, i, err := cborHead(src)
+	if err != nil {
+		return dst, 0, err
+	}
+	switch major {
+	case cborUint:
+		dst = strconv.AppendUint(dst, n, 10)
+	case cborNegint:
+		switch {
+		case n <= math.MaxInt64:
+			dst = strconv.AppendInt(dst, -1-int64(n), 10)
+		case n < math.MaxUint64:
+			dst = append(dst, '-')
+			dst = strconv.AppendUint(dst, n+1, 10)
+		default:
+			dst = append(dst, "-18446744073709551616"...)
+		}
+	case cborBytes, cborText:
+		var b []byte
+		if b, i, err = cborString(src, major, info, n, i); err != nil {
+			return dst, 0, err
+		}
+		dst = append(dst, '"')
+		if major == cborBytes {
+			n := len(dst)
+			dst = append(dst, make([]byte, base64.StdEncoding.EncodedLen(len(b)))...)
+			base64.StdEncoding.Encode(dst[n:], b)
+		} else {
+			e := Entry{buf: dst}
+			e.bytes(b)
+			dst = e.buf
+		}
+		dst = append(dst, '"')
+	case cborArray, cborMap:
+		if major == cborArray {
+			dst = append(dst, '[')
+		} else {
+			dst = append(dst, '{')
+		}
+		for k := uint64(0); info == cborIndefinite || k < n; k++ {
+			if info == cborIndefinite {
+				if i >= len(src) {
+					return dst, 0, io.ErrUnexpectedEOF
+				}
+				if src[i] == cborBreak {
+					i++
+					break
+				}
+			}
+			if k != 0 {
+				dst = append(dst, ',')
+			}
+			var m int
+			if major == cborMap {
+				if dst, m, err = cborKeyToJSON(dst, src[i:], depth+1); err != nil {
+					return dst, 0, err
+				}
+				i += m
+				dst = append(dst, ':')
+			}
+			if dst, m, err = cborToJSON(dst, src[i:], depth+1); err != nil {
+				return dst, 0, err
+			}
+			i += m
+		}
+		if major == cborArray {
+			dst = append(dst, ']')
+		} else {
+			dst = append(dst, '}')
+		}
+	case cborTag:
+		var m int
+		if dst, m, err = cborToJSON(dst, src[i:], depth+1); err != nil {
+			return dst, 0, err
+		}
+		i += m
+	case cborSimple:
+		switch info {
+		case 20:
+			dst = append(dst, "false"...)
+		case 21:
+			dst = append(dst, "true"...)
+		case 25:
+			dst = appendFloat(dst, float64(cborHalf(uint16(n))), 32)
+		case 26:
+			dst = appendFloat(dst, float64(math.Float32frombits(uint32(n))), 32)
+		case 27:
+			dst = appendFloat(dst, math.Float64frombits(n), 64)
+		case cborIndefinite:
+			return dst, 0, errInvalidCBOR
+		default:
+			dst = append(dst, "null"...)
+		}
+	}
+	return dst, i, nil
+}
+
+// cborKeyToJSON appends the map key at the beginning of src as a JSON string to dst.
+func cborKeyToJSON(dst, src []byte, depth int) ([]byte, int, error) {
+	if len(src) != 0 && src[0]&0xe0 == cborText {
+		return cborToJSON(dst, src, depth)
+	}
+	b := bbpool.Get().(*bb)
+	var n int
+	var err error
+	if b.B, n, err = cborToJSON(b.B[:0], src, depth); err == nil {
+		if len(b.B) != 0 && b.B[0] == '"' {
+			dst = append(dst, b.B...)
+		} else {
+			dst = append(dst, '"')
+			e := Entry{buf: dst}
+			e.bytes(b.B)
+			dst = append(e.buf, '"')
+		}
+	}
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+	if err != nil {
+		return dst, 0, err
+	}
+	return dst, n, nil
+}
+
+// cborHead decodes the head of CBOR data item at the beginning of src, and returns
+// the major type, additional info, argument and length of head.
+func cborHead(src []byte) (major, info byte, n uint64, i int, err error) {
+	if len(src) == 0 {
+		return 0, 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	major, info = src[0]&0xe0, src[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), 1, nil
+	case info == 24:
+		i = 2
+	case info == 25:
+		i = 3
+	case info == 26:
+		i = 5
+	case info == 27:
+		i = 9
+	case info == cborIndefinite:
+		if major == cborUint || major == cborNegint || major == cborTag {
+			return 0, 0, 0, 0, errInvalidCBOR
+		}
+		return major, info, 0, 1, nil
+	default:
+		return 0, 0, 0, 0, errInvalidCBOR
+	}
+	if len(src) < i {
+		return 0, 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	switch i {
+	case 2:
+		n = uint64(src[1])
+	case 3:
+		n = uint64(binary.BigEndian.Uint16(src[1:]))
+	case 5:
+		n = uint64(binary.BigEndian.Uint32(src[1:]))
+	case 9:
+		n = binary.BigEndian.Uint64(src[1:])
+	}
+	return
+}
+
+// cborString returns the content of byte or text string, concatenating the chunks of indefinite length.
+func cborString(src []byte, major, info byte, n uint64, i int) ([]byte, int, error) {
+	if info != cborIndefinite {
+		if uint64(len(src)-i) < n {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		return src[i : i+int(n)], i + int(n), nil
+	}
+	var b []byte
+	for {
+		if i >= len(src) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		if src[i] == cborBreak {
+			return b, i + 1, nil
+		}
+		m, info, n, j, err := cborHead(src[i:])
+		if err != nil {
+			return nil, 0, err
+		}
+		if m != major || info == cborIndefinite {
+			return nil, 0, errInvalidCBOR
+		}
+		i += j
+		if uint64(len(src)-i) < n {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		b = append(b, src[i:i+int(n)]...)
+		i += int(n)
+	}
+}
+
+// cborHalf converts the IEEE 754 half-precision float to float32.
+func cborHalf(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch exp {
+	case 0:
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
+
+// CBORReader reads the CBOR sequence of entries written by CBOREncoding from Reader,
+// and returns them as JSON lines, e.g.
+//
+//	io.Copy(os.Stdout, log.NewCBORReader(file))
+type CBORReader struct {
+	// Reader specifies the reader of CBOR sequence.
+	Reader io.Reader
+
+	buf  []byte // undecoded cbor
+	line []byte // json line
+	off  int    // read offset of line
+	err  error  // error of Reader
+}
+
+// NewCBORReader returns a CBORReader which reads from r.
+func NewCBORReader(r io.Reader) *CBORReader {
+	return &CBORReader{Reader: r}
+}
+
+// Read implements io.Reader.
+func (r *CBORReader) Read(p []byte) (n int, err error) {
+	for r.off == len(r.line) {
+		r.line, r.off = r.line[:0], 0
+		if len(r.buf) != 0 {
+			line, n, err := CBORToJSON(r.line, r.buf)
+			if err == nil {
+				r.line = append(line, '\n')
+				r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+				break
+			}
+			if err != io.ErrUnexpectedEOF {
+				return 0, err
+			}
+			if r.err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		if cap(r.buf)-len(r.buf) < 4096 {
+			r.buf = append(make([]byte, 0, 2*cap(r.buf)+4096), r.buf...)
+		}
+		n, err := r.Reader.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+n]
+		r.err = err
+	}
+	n = copy(p, r.line[r.off:])
+	r.off += n
+	return n, nil
+}
@@ -0,0 +1,269 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type cborTestObject struct {
+	Name string
+	Age  int
+}
+
+func (o *cborTestObject) MarshalObject(e *Entry) {
+	e.Str("name", o.Name).Int("age", o.Age)
+}
+
+func cborTestEntry(logger *Logger) {
+	logger.Info().
+		Str("str", "a \"quoted\"\tstring 中文").
+		Bytes("bytes", []byte("<html>")).
+		Int("int", -42).
+		Int64("int64", -1<<63).
+		Uint64("uint64", 1<<64-1).
+		Uint8("uint8", 200).
+		Float64("float64", 3.14159).
+		Float32("float32", 1.1).
+		Floats64("floats", []float64{1, -2.5}).
+		Bool("bool", true).
+		Bools("bools", []bool{true, false}).
+		Time("time1", time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)).
+		Times("times", []time.Time{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}).
+		Dur("dur", 1500*time.Microsecond).
+		Dur("dur2", -2*time.Second).
+		Err(errors.New("an error")).
+		AnErr("nil", nil).
+		Strs("strs", []string{"a", "b\nc"}).
+		IPAddr("ip", net.IP{192, 168, 0, 1}).
+		Hex("hex", []byte{0xde, 0xad}).
+		Dict("dict", NewContext(nil).Str("a", "b").Int("n", 1).Value()).
+		Object("object", &cborTestObject{"jack", 18}).
+		Any("any", map[string]any{"x": []int{1, 2}}).
+		RawJSON("raw", []byte(`{"k":[null,true]}`)).
+		Stringer("stringer", nil).
+		Msg("hello cbor")
+}
+
+func TestCBOREncoding(t *testing.T) {
+	var jsonBuf, cborBuf bytes.Buffer
+
+	cborTestEntry(&Logger{
+		Level:      InfoLevel,
+		TimeFormat: TimeFormatUnixMs,
+		Context:    NewContext(nil).Str("ctx", "value").Value(),
+		Writer:     IOWriter{&jsonBuf},
+	})
+	cborTestEntry(&Logger{
+		Level:      InfoLevel,
+		TimeFormat: TimeFormatUnixMs,
+		Context:    NewContext(nil).Str("ctx", "value").Value(),
+		Encoding:   CBOREncoding,
+		Writer:     IOWriter{&cborBuf},
+	})
+
+	if bytes.Contains(cborBuf.Bytes(), []byte(`"str"`)) {
+		t.Fatalf("cbor entry should not contain json: %q", cborBuf.Bytes())
+	}
+
+	line, n, err := CBORToJSON(nil, cborBuf.Bytes())
+	if err != nil {
+		t.Fatalf("CBORToJSON error: %+v", err)
+	}
+	if n != cborBuf.Len() {
+		t.Errorf("CBORToJSON should read the whole entry: %d != %d", n, cborBuf.Len())
+	}
+
+	var want, got map[string]any
+	if err := json.Unmarshal(jsonBuf.Bytes(), &want); err != nil {
+		t.Fatalf("json unmarshal error: %+v", err)
+	}
+	if err := json.Unmarshal(line, &got); err != nil {
+		t.Fatalf("json unmarshal error: %+v, line: %s", err, line)
+	}
+	if got["bytes"] != "PGh0bWw+" {
+		t.Errorf("cbor byte string should be decoded as base64: %v", got["bytes"])
+	}
+	delete(want, "time")
+	delete(got, "time")
+	delete(want, "bytes")
+	delete(got, "bytes")
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("decoded cbor entry mismatch\nwant: %s\ngot:  %s", jsonBuf.Bytes(), line)
+	}
+}
+
+func cborTestHex(s string) ([]byte, error) {
+	b := make([]byte, len(s)/2)
+	_, err := fmt.Sscanf(s, "%x", &b)
+	return b, err
+}
+
+func TestCBORToJSON(t *testing.T) {
+	cases := []struct {
+		CBOR string
+		JSON string
+	}{
+		{"00", `0`},
+		{"1903e8", `1000`},
+		{"3903e7", `-1000`},
+		{"1bffffffffffffffff", `18446744073709551615`},
+		{"3bffffffffffffffff", `-18446744073709551616`},
+		{"f93c00", `1`},
+		{"fa47c35000", `100000`},
+		{"fb3ff199999999999a", `1.1`},
+		{"f97c00", `"+Inf"`},
+		{"f4", `false`},
+		{"f5", `true`},
+		{"f6", `null`},
+		{"f7", `null`},
+		{"4401020304", `"AQIDBA=="`},
+		{"62225c", `"\"\\"`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"a201020304", `{"1":2,"3":4}`},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+	}
+
+	for _, c := range cases {
+		src, _ := cborTestHex(c.CBOR)
+		dst, n, err := CBORToJSON(nil, src)
+		if err != nil {
+			t.Errorf("CBORToJSON(%s) error: %+v", c.CBOR, err)
+			continue
+		}
+		if string(dst) != c.JSON || n != len(src) {
+			t.Errorf("CBORToJSON(%s) = %s, %d, want %s, %d", c.CBOR, dst, n, c.JSON, len(src))
+		}
+	}
+
+	for _, s := range []string{"", "18", "bf6161", "9f01", "7f6161"} {
+		src, _ := cborTestHex(s)
+		if _, _, err := CBORToJSON(nil, src); err != io.ErrUnexpectedEOF {
+			t.Errorf("CBORToJSON(%s) should return io.ErrUnexpectedEOF: %+v", s, err)
+		}
+	}
+	for _, s := range []string{"1c", "ff", "1f", "7f01ff"} {
+		src, _ := cborTestHex(s)
+		if _, _, err := CBORToJSON(nil, src); err == nil || err == io.ErrUnexpectedEOF {
+			t.Errorf("CBORToJSON(%s) should return an invalid error: %+v", s, err)
+		}
+	}
+}
+
+func TestCBORReader(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: CBOREncoding,
+		Writer:   IOWriter{&buf},
+	}
+	for i := 0; i < 3; i++ {
+		logger.Info().Int("i", i).Str("pad", strings.Repeat("x", 3000)).Msg("hello")
+	}
+
+	data, err := io.ReadAll(NewCBORReader(iotest.OneByteReader(bytes.NewReader(buf.Bytes()))))
+	if err != nil {
+		t.Fatalf("read error: %+v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), data)
+	}
+	for i, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("json unmarshal error: %+v", err)
+		}
+		if m["i"] != float64(i) || m["message"] != "hello" || m["level"] != "info" {
+			t.Errorf("unexpected line: %s", line)
+		}
+	}
+
+	_, err = io.ReadAll(NewCBORReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1])))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("read truncated cbor should return io.ErrUnexpectedEOF: %+v", err)
+	}
+}
+
+func TestCBOREncodingConsoleWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: CBOREncoding,
+		Writer:   &ConsoleWriter{Writer: &buf},
+	}
+	logger.Info().Str("foo", "bar").Msg("hello console")
+
+	if s := buf.String(); !strings.Contains(s, "hello console") || !strings.Contains(s, "foo=bar") {
+		t.Errorf("console writer should decode cbor entry: %q", s)
+	}
+}
+
+func TestCBOREncodingBytes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: CBOREncoding,
+		Writer:   IOWriter{&buf},
+	}
+	logger.Info().Bytes("data", []byte{0xff, 0x00}).BytesOrNil("nil", nil).Msg("hello")
+
+	if !bytes.Contains(buf.Bytes(), []byte{0x42, 0xff, 0x00}) {
+		t.Errorf("cbor entry should contain a byte string: %x", buf.Bytes())
+	}
+
+	line, _, err := CBORToJSON(nil, buf.Bytes())
+	if err != nil {
+		t.Fatalf("CBORToJSON error: %+v", err)
+	}
+	if s := string(line); !strings.Contains(s, `"data":"/wA="`) || !strings.Contains(s, `"nil":null`) || !strings.Contains(s, `"message":"hello"`) {
+		t.Errorf("cbor byte string should be decoded as base64: %s", s)
+	}
+}
+
+func TestCBOREncodingRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: CBOREncoding,
+		Redactor: &Redactor{Keys: []string{"password", "user.token"}},
+		Writer:   IOWriter{&buf},
+	}
+	logger.Info().
+		Str("password", "secret").
+		Dict("user", NewContext(nil).Str("token", "abc").Value()).
+		Msg("login")
+
+	line, _, err := CBORToJSON(nil, buf.Bytes())
+	if err != nil {
+		t.Fatalf("CBORToJSON error: %+v", err)
+	}
+	if s := string(line); strings.Contains(s, "secret") || strings.Contains(s, "abc") || !strings.Contains(s, `"token":"[REDACTED]"`) {
+		t.Errorf("cbor entry should be redacted: %s", s)
+	}
+}
+
+func BenchmarkLoggerCBOR(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Encoding:   CBOREncoding,
+		Writer:     IOWriter{io.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Msgf("hello %s", "world")
+	}
+}
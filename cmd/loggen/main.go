@@ -0,0 +1,543 @@
+// Command loggen generates the MarshalObject methods of structs, which
+// implement log.ObjectMarshaler by the typed Entry methods without reflection.
+//
+// Usage:
+//
+//	//go:generate go run github.com/phuslu/log/cmd/loggen -type=User,Request
+//
+// The fields are named and omitted by their json tags, the fields of embedded
+// structs are promoted and hide each other by the rules of encoding/json. A field tagged with `log:"-"` is skipped, and with
+// `log:",redact"` is written by Entry.Redacted with the Redactor of logger. The fields of types without a
+// typed Entry method are written by Entry.Any.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	types := flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_marshal.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: loggen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	names := strings.Split(*types, ",")
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(names[0])+"_marshal.go")
+	}
+
+	src, err := generate(dir, names, filepath.Base(*output))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loggen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "loggen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// typeDecl is a type declared in the package, with the imports of its file.
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	imports map[string]string // name -> path
+}
+
+type generator struct {
+	pkg        string
+	decls      map[string]typeDecl
+	marshalers map[string]bool
+	buf        bytes.Buffer
+}
+
+// generate returns the formatted source of MarshalObject methods of the types in the package of dir.
+func generate(dir string, names []string, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages found in %s", len(pkgs), dir)
+	}
+
+	g := &generator{
+		decls:      make(map[string]typeDecl),
+		marshalers: make(map[string]bool),
+	}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		files := make([]string, 0, len(pkg.Files))
+		for filename := range pkg.Files {
+			files = append(files, filename)
+		}
+		sort.Strings(files)
+		for _, filename := range files {
+			g.parseFile(pkg.Files[filename])
+		}
+	}
+	for _, name := range names {
+		g.marshalers[name] = true
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by \"loggen -type=%s\"; DO NOT EDIT.\n\n", strings.Join(names, ","))
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkg)
+	fmt.Fprintf(&g.buf, "import \"github.com/phuslu/log\"\n")
+	for _, name := range names {
+		if err := g.generate(name); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w\n%s", err, g.buf.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) parseFile(file *ast.File) {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndexByte(path, '/')+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				g.decls[spec.Name.Name] = typeDecl{spec, imports}
+			}
+		case *ast.FuncDecl:
+			if decl.Name.Name != "MarshalObject" || decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				g.marshalers[ident.Name] = true
+			}
+		}
+	}
+}
+
+func (g *generator) generate(name string) error {
+	decl, ok := g.decls[name]
+	if !ok {
+		return fmt.Errorf("type %s not found", name)
+	}
+	if decl.spec.TypeParams != nil && len(decl.spec.TypeParams.List) != 0 {
+		return fmt.Errorf("type %s is generic", name)
+	}
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		return fmt.Errorf("type %s is not a struct", name)
+	}
+
+	fields, err := g.fields(nil, st, decl.imports, "v", 0, nil, map[string]bool{name: true})
+	if err != nil {
+		return fmt.Errorf("type %s: %w", name, err)
+	}
+
+	fmt.Fprintf(&g.buf, "\n// MarshalObject implements log.ObjectMarshaler.\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) MarshalObject(e *log.Entry) {\n", name)
+	var guards []string
+	for _, f := range dominantFields(fields) {
+		// close the nil checks of embedded pointers not shared by f, then open its own
+		n := 0
+		for n < len(guards) && n < len(f.guards) && guards[n] == f.guards[n] {
+			n++
+		}
+		for ; len(guards) > n; guards = guards[:len(guards)-1] {
+			fmt.Fprintf(&g.buf, "}\n")
+		}
+		for _, guard := range f.guards[n:] {
+			fmt.Fprintf(&g.buf, "if %s != nil {\n", guard)
+			guards = append(guards, guard)
+		}
+		if f.embed != "" {
+			fmt.Fprintf(&g.buf, "%s\n", f.embed)
+			continue
+		}
+		if err := g.field(f.key, f.path, f.typ, f.imports, f.omitempty, f.redact); err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+	}
+	for range guards {
+		fmt.Fprintf(&g.buf, "}\n")
+	}
+	fmt.Fprintf(&g.buf, "}\n")
+	return nil
+}
+
+// structField is a field of struct, or of its embedded structs at depth.
+type structField struct {
+	key       string
+	path      string
+	typ       ast.Expr
+	imports   map[string]string
+	depth     int
+	tagged    bool
+	omitempty bool
+	redact    bool
+	guards    []string // the paths of embedded pointers which must be non-nil
+	embed     string   // the statement of an embedded ObjectMarshaler, whose keys are unknown
+}
+
+// fields appends the fields of st, which is accessed by path, to dst.
+func (g *generator) fields(dst []structField, st *ast.StructType, imports map[string]string, path string, depth int, guards []string, seen map[string]bool) ([]structField, error) {
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			s, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(s)
+		}
+		jsonTag := tag.Get("json")
+		if jsonTag == "-" || tag.Get("log") == "-" {
+			continue
+		}
+		key, opts, _ := strings.Cut(jsonTag, ",")
+		_, logOpts, _ := strings.Cut(tag.Get("log"), ",")
+		f := structField{
+			key:       key,
+			imports:   imports,
+			depth:     depth,
+			tagged:    key != "",
+			omitempty: hasOption(opts, "omitempty"),
+			redact:    hasOption(logOpts, "redact"),
+			guards:    guards,
+		}
+
+		if len(field.Names) == 0 {
+			// embedded
+			typ, ptr := field.Type, false
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ, ptr = star.X, true
+			}
+			name := typeName(typ)
+			if key == "" {
+				if decl, ok := g.decls[name]; ok && isIdent(typ) {
+					var err error
+					if dst, err = g.embedded(dst, name, decl, path+"."+name, ptr, f, seen); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			if !ast.IsExported(name) {
+				continue
+			}
+			if f.key == "" {
+				f.key = name
+			}
+			f.path, f.typ = path+"."+name, field.Type
+			dst = append(dst, f)
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			f := f
+			if f.key == "" {
+				f.key = ident.Name
+			}
+			f.path, f.typ = path+"."+ident.Name, field.Type
+			dst = append(dst, f)
+		}
+	}
+	return dst, nil
+}
+
+// embedded appends the fields promoted from embedded struct name to dst, f is the embedding field.
+func (g *generator) embedded(dst []structField, name string, decl typeDecl, path string, ptr bool, f structField, seen map[string]bool) ([]structField, error) {
+	if g.marshalers[name] {
+		if ptr {
+			f.embed = fmt.Sprintf("e.EmbedObject(%s)", path)
+		} else {
+			f.embed = fmt.Sprintf("e.EmbedObject(&%s)", path)
+		}
+		return append(dst, f), nil
+	}
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		f.key, f.path, f.typ, f.omitempty, f.redact = name, path, &ast.Ident{Name: name}, false, false
+		return append(dst, f), nil
+	}
+	if seen[name] {
+		return nil, fmt.Errorf("recursive embedded struct %s", name)
+	}
+	seen[name] = true
+	defer delete(seen, name)
+	guards := f.guards
+	if ptr {
+		guards = append(guards[:len(guards):len(guards)], path)
+	}
+	return g.fields(dst, st, decl.imports, path, f.depth+1, guards, seen)
+}
+
+// dominantFields returns the fields which are not hidden by the fields of the same key,
+// as encoding/json, the shallowest field wins, or the only tagged field of the
+// shallowest ones, otherwise none.
+func dominantFields(fields []structField) []structField {
+	type candidate struct{ depth, index, count, tagged, taggedIndex int }
+	keys := make(map[string]*candidate)
+	for i, f := range fields {
+		if f.embed != "" {
+			continue
+		}
+		c := keys[f.key]
+		if c == nil || f.depth < c.depth {
+			c = &candidate{depth: f.depth, index: i}
+			keys[f.key] = c
+		} else if f.depth > c.depth {
+			continue
+		}
+		c.count++
+		if f.tagged {
+			c.tagged++
+			c.taggedIndex = i
+		}
+	}
+	var dominant []structField
+	for i, f := range fields {
+		if c := keys[f.key]; f.embed != "" || (c.count == 1 && c.index == i) || (c.count > 1 && c.tagged == 1 && c.taggedIndex == i) {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+var basicMethods = map[string]string{
+	"string":  "Str",
+	"bool":    "Bool",
+	"int":     "Int",
+	"int8":    "Int8",
+	"int16":   "Int16",
+	"int32":   "Int32",
+	"rune":    "Int32",
+	"int64":   "Int64",
+	"uint":    "Uint",
+	"uint8":   "Uint8",
+	"byte":    "Uint8",
+	"uint16":  "Uint16",
+	"uint32":  "Uint32",
+	"uint64":  "Uint64",
+	"float32": "Float32",
+	"float64": "Float64",
+	"error":   "AnErr",
+}
+
+var sliceMethods = map[string]string{
+	"string":  "Strs",
+	"bool":    "Bools",
+	"byte":    "Bytes",
+	"int":     "Ints",
+	"int8":    "Ints8",
+	"int16":   "Ints16",
+	"int32":   "Ints32",
+	"int64":   "Ints64",
+	"uint":    "Uints",
+	"uint8":   "Bytes",
+	"uint16":  "Uints16",
+	"uint32":  "Uints32",
+	"uint64":  "Uints64",
+	"float32": "Floats32",
+	"float64": "Floats64",
+	"error":   "Errs",
+
+	"time.Time":      "Times",
+	"time.Duration":  "Durs",
+	"net/netip.Addr": "NetIPAddrs",
+}
+
+var namedMethods = map[string]string{
+	"time.Time":                "Time",
+	"time.Duration":            "Dur",
+	"net.IP":                   "IPAddr",
+	"net.IPNet":                "IPPrefix",
+	"net.HardwareAddr":         "MACAddr",
+	"net/netip.Addr":           "NetIPAddr",
+	"net/netip.AddrPort":       "NetIPAddrPort",
+	"net/netip.Prefix":         "NetIPPrefix",
+	"encoding/json.RawMessage": "RawJSON",
+}
+
+// field writes the statement of a field.
+func (g *generator) field(key, path string, typ ast.Expr, imports map[string]string, omitempty, redact bool) error {
+	quoted := strconv.Quote(key)
+	if redact {
+		fmt.Fprintf(&g.buf, "e.Redacted(%s, %s)\n", quoted, path)
+		return nil
+	}
+
+	stmt, empty := g.method(quoted, path, typ, imports)
+	if omitempty && empty != "" {
+		fmt.Fprintf(&g.buf, "if %s {\n%s\n}\n", empty, stmt)
+	} else {
+		fmt.Fprintf(&g.buf, "%s\n", stmt)
+	}
+	return nil
+}
+
+// method returns the statement which writes the field of typ, and the condition of non-empty value.
+func (g *generator) method(key, path string, typ ast.Expr, imports map[string]string) (stmt string, nonempty string) {
+	fallback := fmt.Sprintf("e.Any(%s, %s)", key, path)
+
+	switch t := typ.(type) {
+	case *ast.Ident:
+		if method, ok := basicMethods[t.Name]; ok {
+			return fmt.Sprintf("e.%s(%s, %s)", method, key, path), nonEmpty(t.Name, path)
+		}
+		decl, ok := g.decls[t.Name]
+		if !ok {
+			return fallback, ""
+		}
+		if g.marshalers[t.Name] {
+			return fmt.Sprintf("e.Object(%s, &%s)", key, path), ""
+		}
+		// the named types of basic types
+		if u, ok := decl.spec.Type.(*ast.Ident); ok && decl.spec.Assign == 0 {
+			if method, ok := basicMethods[u.Name]; ok && u.Name != "error" {
+				return fmt.Sprintf("e.%s(%s, %s(%s))", method, key, u.Name, path), nonEmpty(u.Name, path)
+			}
+		}
+		switch decl.spec.Type.(type) {
+		case *ast.ArrayType, *ast.MapType:
+			return fallback, fmt.Sprintf("len(%s) != 0", path)
+		case *ast.StarExpr, *ast.InterfaceType:
+			return fallback, fmt.Sprintf("%s != nil", path)
+		}
+		return fallback, ""
+	case *ast.SelectorExpr:
+		name := qualifiedName(t, imports)
+		if method, ok := namedMethods[name]; ok {
+			switch name {
+			case "time.Duration":
+				return fmt.Sprintf("e.%s(%s, %s)", method, key, path), path + " != 0"
+			case "net.IP", "net.HardwareAddr", "encoding/json.RawMessage":
+				return fmt.Sprintf("e.%s(%s, %s)", method, key, path), fmt.Sprintf("len(%s) != 0", path)
+			}
+			return fmt.Sprintf("e.%s(%s, %s)", method, key, path), ""
+		}
+		return fallback, ""
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok && g.marshalers[ident.Name] {
+			return fmt.Sprintf("e.Object(%s, %s)", key, path), path + " != nil"
+		}
+		return fallback, path + " != nil"
+	case *ast.ArrayType:
+		nonempty = fmt.Sprintf("len(%s) != 0", path)
+		if t.Len != nil {
+			return fallback, nonempty
+		}
+		var elem string
+		switch et := t.Elt.(type) {
+		case *ast.Ident:
+			elem = et.Name
+		case *ast.SelectorExpr:
+			elem = qualifiedName(et, imports)
+		case *ast.StarExpr:
+			if ident, ok := et.X.(*ast.Ident); ok && g.marshalers[ident.Name] {
+				return fmt.Sprintf("e.Objects(%s, %s)", key, path), nonempty
+			}
+		}
+		if method, ok := sliceMethods[elem]; ok {
+			return fmt.Sprintf("e.%s(%s, %s)", method, key, path), nonempty
+		}
+		return fallback, nonempty
+	case *ast.MapType:
+		return fallback, fmt.Sprintf("len(%s) != 0", path)
+	case *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return fallback, path + " != nil"
+	}
+	return fallback, ""
+}
+
+// nonEmpty returns the condition of non-empty value of basic type.
+func nonEmpty(basic, path string) string {
+	switch basic {
+	case "string":
+		return path + ` != ""`
+	case "bool":
+		return path
+	case "error":
+		return path + " != nil"
+	}
+	return path + " != 0"
+}
+
+// qualifiedName returns the import path qualified name of a selector, e.g. "net/netip.Addr".
+func qualifiedName(sel *ast.SelectorExpr, imports map[string]string) string {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	path, ok := imports[x.Name]
+	if !ok {
+		return ""
+	}
+	return path + "." + sel.Sel.Name
+}
+
+func typeName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func isIdent(typ ast.Expr) bool {
+	_, ok := typ.(*ast.Ident)
+	return ok
+}
+
+func hasOption(opts, name string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("testdata/example", []string{"User", "Address"}, "user_marshal.go")
+	if err != nil {
+		t.Fatalf("generate error: %+v", err)
+	}
+
+	for _, s := range []string{
+		`// Code generated by "loggen -type=User,Address"; DO NOT EDIT.`,
+		`func (v *User) MarshalObject(e *log.Entry) {`,
+		`e.Int64("id", v.Base.ID)`,
+		`e.Time("created", v.Base.Created)`,
+		"if v.Meta != nil {\n\t\tif v.Meta.Trace != \"\" {",
+		`e.Str("name", v.Name)`,
+		"if v.Age != 0 {\n\t\te.Int(\"age\", v.Age)\n\t}",
+		`e.Str("status", string(v.Status))`,
+		"if len(v.Tags) != 0 {\n\t\te.Strs(\"tags\", v.Tags)\n\t}",
+		`e.Floats64("scores", v.Scores)`,
+		`e.NetIPAddr("ip", v.IP)`,
+		`e.Dur("timeout", v.Timeout)`,
+		`e.Object("address", &v.Address)`,
+		"if v.Previous != nil {\n\t\te.Object(\"previous\", v.Previous)\n\t}",
+		`e.Objects("history", v.History)`,
+		"if len(v.Labels) != 0 {\n\t\te.Any(\"labels\", v.Labels)\n\t}",
+		"if len(v.Raw) != 0 {\n\t\te.RawJSON(\"raw\", v.Raw)\n\t}",
+		`e.AnErr("err", v.Err)`,
+		`e.Redacted("password", v.Password)`,
+		`func (v *Address) MarshalObject(e *log.Entry) {`,
+	} {
+		if !bytes.Contains(src, []byte(s)) {
+			t.Errorf("generated source should contain %q:\n%s", s, src)
+		}
+	}
+	for _, s := range []string{"Internal", "Ignored", "private"} {
+		if bytes.Contains(src, []byte(s)) {
+			t.Errorf("generated source should not contain %q:\n%s", s, src)
+		}
+	}
+
+	// the promoted fields hide each other by the rules of encoding/json
+	src, err = generate("testdata/example", []string{"Event"}, "event_marshal.go")
+	if err != nil {
+		t.Fatalf("generate error: %+v", err)
+	}
+	for _, s := range []string{
+		`e.Int64("id", v.Base.ID)`,
+		`e.Str("Note", v.Comment.Note)`,
+		`e.Str("kind", v.Kind)`,
+		`e.Str("created", v.Created)`,
+	} {
+		if !bytes.Contains(src, []byte(s)) {
+			t.Errorf("generated source should contain %q:\n%s", s, src)
+		}
+	}
+	for _, s := range []string{"v.Base.Created", "Trace", "v.Tracking.Note", "Label", "v.Meta"} {
+		if bytes.Contains(src, []byte(s)) {
+			t.Errorf("generated source should not contain %q:\n%s", s, src)
+		}
+	}
+
+	if _, err := generate("testdata/example", []string{"Unknown"}, ""); err == nil {
+		t.Errorf("generate unknown type should return an error")
+	}
+	if _, err := generate("testdata/example", []string{"Status"}, ""); err == nil {
+		t.Errorf("generate non-struct type should return an error")
+	}
+}
+
+func TestGenerateBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build test in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, _ := filepath.Abs("../..")
+
+	dir := t.TempDir()
+	src, _ := os.ReadFile("testdata/example/example.go")
+	gen, err := generate("testdata/example", []string{"User", "Address", "Event"}, "user_marshal.go")
+	if err != nil {
+		t.Fatalf("generate error: %+v", err)
+	}
+	files := map[string]string{
+		"go.mod":          "module example\n\ngo 1.18\n\nrequire github.com/phuslu/log v0.0.0\n\nreplace github.com/phuslu/log => " + root + "\n",
+		"example.go":      string(src),
+		"user_marshal.go": string(gen),
+		"example_test.go": `package example
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+func TestMarshalObject(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.Logger{Writer: log.IOWriter{Writer: &buf}}
+	user := &User{Base: Base{ID: 1, Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}, Name: "jack", Status: "active", Address: Address{City: "NYC"}, Password: "secret"}
+	logger.Info().Object("user", user).Msg("")
+	want := ` + "`" + `"user":{"id":1,"created":"2020-01-02T03:04:05Z","name":"jack","status":"active","scores":[],"ip":"","timeout":0,"address":{"city":"NYC"},"history":[],"err":null,"password":"[REDACTED]"}` + "`" + `
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Fatalf("want %s, got %s", want, buf.Bytes())
+	}
+
+	// the redacted fields are hashed by the Redactor of logger as the fields redacted by key
+	buf.Reset()
+	logger.Redactor = &log.Redactor{Keys: []string{"pwd"}, Hash: true, HashKey: []byte("key")}
+	logger.Info().Object("user", user).Str("pwd", "secret").Msg("")
+	var m struct {
+		User struct {
+			Password string ` + "`" + `json:"password"` + "`" + `
+		} ` + "`" + `json:"user"` + "`" + `
+		Pwd string ` + "`" + `json:"pwd"` + "`" + `
+	}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || m.Pwd == "secret" || m.User.Password != m.Pwd {
+		t.Fatalf("redacted field must be hashed by the redactor of logger: %s", buf.Bytes())
+	}
+}
+
+func TestMarshalObjectEmbedded(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.Logger{Writer: log.IOWriter{Writer: &buf}}
+	event := &Event{
+		Base:     Base{ID: 1},
+		Meta:     &Meta{Trace: "meta"},
+		Tracking: Tracking{Trace: "tracking", Note: "tracking", Label: "tracking"},
+		Comment:  Comment{Note: "comment", Label: "comment"},
+		Kind:     "click",
+		Created:  "today",
+	}
+	logger.Info().Object("event", event).Msg("")
+	b, _ := json.Marshal(event)
+	if want := ` + "`" + `"event":` + "`" + ` + string(b); !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Fatalf("want %s, got %s", want, buf.Bytes())
+	}
+}
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(gobin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test generated code error: %+v\n%s", err, strings.TrimSpace(string(out)))
+	}
+}
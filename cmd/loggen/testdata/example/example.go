@@ -0,0 +1,65 @@
+package example
+
+import (
+	"encoding/json"
+	"net/netip"
+	"time"
+)
+
+type Status string
+
+type Base struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type Meta struct {
+	Trace string `json:"trace,omitempty"`
+}
+
+type Address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type User struct {
+	Base
+	*Meta
+	Name     string            `json:"name"`
+	Age      int               `json:"age,omitempty"`
+	Status   Status            `json:"status"`
+	Tags     []string          `json:"tags,omitempty"`
+	Scores   []float64         `json:"scores"`
+	IP       netip.Addr        `json:"ip"`
+	Timeout  time.Duration     `json:"timeout"`
+	Address  Address           `json:"address"`
+	Previous *Address          `json:"previous,omitempty"`
+	History  []*Address        `json:"history"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Err      error             `json:"err"`
+	Password string            `json:"password" log:",redact"`
+	Internal string            `log:"-"`
+	Ignored  string            `json:"-"`
+	private  string
+}
+
+type Tracking struct {
+	Trace string `json:"trace"`
+	Note  string
+	Label string
+}
+
+type Comment struct {
+	Note  string `json:"Note"`
+	Label string
+}
+
+type Event struct {
+	Base
+	*Meta
+	Tracking
+	Comment
+	Kind    string `json:"kind"`
+	Created string `json:"created"`
+}
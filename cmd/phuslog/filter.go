@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// filter selects the records by level, time range and field expressions.
+type filter struct {
+	level log.Level
+	since time.Time
+	until time.Time
+	exprs []expr
+}
+
+// active reports whether the filter selects any records.
+func (f *filter) active() bool {
+	return f.level != 0 || !f.since.IsZero() || !f.until.IsZero() || len(f.exprs) != 0
+}
+
+// match reports whether the record is selected by the filter.
+func (f *filter) match(r *log.Record) bool {
+	if f.level != 0 {
+		if level := r.Level(); level == 0 || level.Compare(f.level) < 0 {
+			return false
+		}
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		t, ok := r.Time()
+		if !ok || (!f.since.IsZero() && t.Before(f.since)) || (!f.until.IsZero() && !t.Before(f.until)) {
+			return false
+		}
+	}
+	for _, e := range f.exprs {
+		if !e.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// expr is a field expression, such as `user.id=42`, `status>=500` or `message~timeout`.
+type expr struct {
+	key   string
+	op    string
+	value string
+	num   float64
+	re    *regexp.Regexp
+}
+
+// parseExpr parses a field expression, the operators are = != ~ !~ > >= < <=,
+// and a bare key matches the records which have the field.
+func parseExpr(s string) (expr, error) {
+	i := strings.IndexAny(s, "=!~<>")
+	if i < 0 {
+		return expr{key: s}, nil
+	}
+	e := expr{key: s[:i]}
+	for _, op := range []string{"!=", "!~", ">=", "<=", "=", "~", ">", "<"} {
+		if strings.HasPrefix(s[i:], op) {
+			e.op, e.value = op, s[i+len(op):]
+			break
+		}
+	}
+	if e.key == "" || e.op == "" {
+		return e, fmt.Errorf("invalid expression %q", s)
+	}
+	switch e.op {
+	case "~", "!~":
+		re, err := regexp.Compile(e.value)
+		if err != nil {
+			return e, fmt.Errorf("invalid expression %q: %v", s, err)
+		}
+		e.re = re
+	case ">", ">=", "<", "<=":
+		f, err := strconv.ParseFloat(e.value, 64)
+		if err != nil {
+			return e, fmt.Errorf("invalid expression %q: %v", s, err)
+		}
+		e.num = f
+	}
+	return e, nil
+}
+
+func (e expr) match(r *log.Record) bool {
+	v, ok := r.Get(e.key)
+	switch e.op {
+	case "":
+		return ok
+	case "!=":
+		return !ok || v.String() != e.value
+	case "!~":
+		return !ok || !e.re.MatchString(v.String())
+	}
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return v.String() == e.value
+	case "~":
+		return e.re.MatchString(v.String())
+	}
+	f, ok := v.Float()
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case ">":
+		return f > e.num
+	case ">=":
+		return f >= e.num
+	case "<":
+		return f < e.num
+	default:
+		return f <= e.num
+	}
+}
+
+// exprs is a flag.Value of repeated field expressions.
+type exprs []expr
+
+func (es *exprs) String() string {
+	return ""
+}
+
+func (es *exprs) Set(s string) error {
+	e, err := parseExpr(s)
+	if err != nil {
+		return err
+	}
+	*es = append(*es, e)
+	return nil
+}
+
+// parseTime parses an RFC3339 time, a date such as 2006-01-02, or a duration before now such as 1h30m.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// follower is an io.Reader of a file which waits for the appended data like `tail -F`.
+// It reopens the path when it points to a new file, e.g. the symlink of log.FileWriter
+// after a rotation, and reads the file from the beginning when it is truncated.
+type follower struct {
+	path     string
+	file     *os.File
+	next     *os.File
+	interval time.Duration
+	done     <-chan struct{}
+}
+
+// newFollower opens path for following, the reads return io.EOF after done is closed.
+func newFollower(path string, interval time.Duration, done <-chan struct{}) (*follower, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &follower{path: path, file: file, interval: interval, done: done}, nil
+}
+
+// Read implements io.Reader, it blocks until there is data to read.
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := f.file.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if f.next != nil {
+			// the rotated file is drained
+			f.file.Close()
+			f.file, f.next = f.next, nil
+			continue
+		}
+		if f.check() {
+			continue
+		}
+		select {
+		case <-f.done:
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// check reports whether there is data to read after the path is rotated or truncated.
+func (f *follower) check() bool {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		// the path is being rotated
+		return false
+	}
+	cur, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	if !os.SameFile(fi, cur) {
+		file, err := os.Open(f.path)
+		if err != nil {
+			return false
+		}
+		// drains the rotated file before reading the new one
+		f.next = file
+		return true
+	}
+	if offset, err := f.file.Seek(0, io.SeekCurrent); err == nil && fi.Size() < offset {
+		_, err = f.file.Seek(0, io.SeekStart)
+		return err == nil
+	}
+	return false
+}
+
+// Close closes the followed files.
+func (f *follower) Close() error {
+	if f.next != nil {
+		f.next.Close()
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,223 @@
+// Command phuslog pretty-prints, colorizes and filters the JSON logs from stdin or files.
+//
+// Usage:
+//
+//	phuslog [flags] [file ...]
+//
+// The entries are rendered by log.ConsoleWriter, or by a text/template of
+// log.FormatterArgs if -template is set. The lines which are not JSON entries
+// are printed as is unless a filter is set. For example,
+//
+//	phuslog -level warn -since 1h -where 'status>=500' -where 'path~^/api/' app.log
+//	kubectl logs -f my-pod | phuslog -end-with-message
+//	phuslog -f -template '{{.Time}} {{.Level}} {{.Message}} user={{.Get "user"}}' /var/log/app.log
+//
+// With -f, the file is followed like `tail -F`, it keeps following the symlink
+// of log.FileWriter across the rotations.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/template"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+func main() {
+	done := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		close(done)
+	}()
+
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, done)
+	switch {
+	case err == flag.ErrHelp:
+	case err == errUsage:
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "phuslog: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage is returned by run if the flags are invalid, the error is already printed with the usage.
+var errUsage = errors.New("invalid flags")
+
+// run runs the command with args, following the file until done is closed.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, done <-chan struct{}) error {
+	var (
+		flags = flag.NewFlagSet("phuslog", flag.ContinueOnError)
+
+		level  = flags.String("level", "", "minimum `level` of entries, e.g. warn")
+		since  = flags.String("since", "", "show entries at or after `time`, RFC3339, a date or a duration before now such as 1h")
+		until  = flags.String("until", "", "show entries before `time`, in the formats of -since")
+		where  exprs
+		color  = flags.String("color", "auto", "colorize output: auto, always or never")
+		quote  = flags.Bool("quote", false, "quote string values")
+		endmsg = flags.Bool("end-with-message", false, "print message at the end of entries")
+		tmpl   = flags.String("template", "", "text/template of log.FormatterArgs to print entries, e.g. '{{.Time}} {{.Message}}'")
+		asjson = flags.Bool("json", false, "print the selected entries as JSON lines")
+		follow = flags.Bool("f", false, "follow the file as it grows and is rotated")
+	)
+	flags.Var(&where, "where", "field `expression` such as key=value, key!=value, key~regexp, key>=num or key, can be repeated")
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: phuslog [flags] [file ...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err != flag.ErrHelp {
+			err = errUsage
+		}
+		return err
+	}
+
+	var f filter
+	var err error
+	if *level != "" {
+		var ok bool
+		if f.level, ok = log.LookupLevel(*level); !ok {
+			return fmt.Errorf("invalid level %q", *level)
+		}
+	}
+	now := time.Now()
+	if f.since, err = parseTime(*since, now); err != nil {
+		return err
+	}
+	if f.until, err = parseTime(*until, now); err != nil {
+		return err
+	}
+	f.exprs = where
+
+	files := flags.Args()
+	if *follow && len(files) != 1 {
+		return errors.New("-f requires exactly one file")
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+
+	p := &printer{filter: f, out: out, json: *asjson}
+	p.console = &log.ConsoleWriter{
+		QuoteString:    *quote,
+		EndWithMessage: *endmsg,
+		Writer:         out,
+	}
+	switch *color {
+	case "always":
+		p.console.ColorOutput = true
+	case "auto":
+		if file, ok := stdout.(*os.File); ok {
+			p.console.ColorOutput = log.IsTerminal(file.Fd())
+		}
+	case "never":
+	default:
+		return fmt.Errorf("invalid color %q", *color)
+	}
+	if *tmpl != "" {
+		t, err := template.New("entry").Parse(*tmpl)
+		if err != nil {
+			return err
+		}
+		p.console.Formatter = templateFormatter(t)
+	}
+
+	if *follow {
+		r, err := newFollower(files[0], 200*time.Millisecond, done)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		return p.print(r, true)
+	}
+	if len(files) == 0 {
+		return p.print(stdin, true)
+	}
+	for _, name := range files {
+		if name == "-" {
+			err = p.print(stdin, true)
+		} else {
+			err = p.printFile(name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printer prints the selected entries.
+type printer struct {
+	filter  filter
+	console *log.ConsoleWriter
+	out     *bufio.Writer
+	json    bool
+	buf     []byte
+}
+
+func (p *printer) printFile(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return p.print(file, false)
+}
+
+// print prints the entries of r, the output is flushed after each entry if stream is true.
+func (p *printer) print(r io.Reader, stream bool) error {
+	d := log.NewDecoder(r)
+	for d.Next() {
+		if err := p.printRecord(d.Record()); err != nil {
+			return err
+		}
+		if stream {
+			if err := p.out.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return d.Err()
+}
+
+func (p *printer) printRecord(r *log.Record) (err error) {
+	p.buf = append(append(p.buf[:0], r.Raw()...), '\n')
+	switch {
+	case !r.Valid():
+		if !p.filter.active() {
+			_, err = p.out.Write(p.buf)
+		}
+	case !p.filter.match(r):
+	case p.json:
+		_, err = p.out.Write(p.buf)
+	default:
+		_, err = p.console.Write(p.buf)
+	}
+	return
+}
+
+// templateFormatter returns a ConsoleWriter.Formatter which executes t, and ends the output with a newline.
+func templateFormatter(t *template.Template) func(io.Writer, *log.FormatterArgs) (int, error) {
+	var b bytes.Buffer
+	return func(w io.Writer, args *log.FormatterArgs) (int, error) {
+		b.Reset()
+		if err := t.Execute(&b, args); err != nil {
+			return 0, err
+		}
+		if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+			b.WriteByte('\n')
+		}
+		return w.Write(b.Bytes())
+	}
+}
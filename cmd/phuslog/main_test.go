@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+func testInput() string {
+	var b bytes.Buffer
+	logger := log.Logger{
+		Level:  log.TraceLevel,
+		Writer: &log.IOWriter{Writer: &b},
+	}
+	logger.Info().Str("path", "/api/users").Int("status", 200).Msg("request")
+	logger.Warn().Str("path", "/api/orders").Int("status", 503).Dict("user", log.NewContext(nil).Int("id", 7).Value()).Msg("request")
+	b.WriteString("plain text line\n")
+	logger.Error().Str("path", "/health").Int("status", 500).Msg("health check failed")
+	logger.Debug().Str("path", "/api/users").Msg("cache miss")
+	return b.String()
+}
+
+func runTest(t *testing.T, input string, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if err := run(args, strings.NewReader(input), &stdout, &stderr, nil); err != nil {
+		t.Fatalf("run %v error: %v %s", args, err, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestRunConsole(t *testing.T) {
+	out := runTest(t, testInput(), "-color", "never")
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("output must have 5 lines, got %d: %s", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "INF > request path=/api/users status=200") {
+		t.Errorf("wrong console output: %s", lines[0])
+	}
+	if lines[2] != "plain text line" {
+		t.Errorf("plain text line must be printed as is: %s", lines[2])
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("output must not be colorized: %s", out)
+	}
+
+	out = runTest(t, testInput(), "-color", "always", "-end-with-message", "-quote")
+	if !strings.Contains(out, "\x1b[") || !strings.Contains(out, `"/api/users"`) || !strings.Contains(out, " request\n") {
+		t.Errorf("output must be colorized, quoted and end with message: %s", out)
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	cases := []struct {
+		Args     []string
+		Messages []string
+	}{
+		{[]string{"-level", "warn"}, []string{`"status":503`, `"status":500`}},
+		{[]string{"-level", "ERROR"}, []string{`"status":500`}},
+		{[]string{"-where", "path~^/api/"}, []string{`"status":200`, `"status":503`, `"cache miss"`}},
+		{[]string{"-where", "path~^/api/", "-where", "status>=500"}, []string{`"status":503`}},
+		{[]string{"-where", "user.id=7"}, []string{`"status":503`}},
+		{[]string{"-where", "user"}, []string{`"status":503`}},
+		{[]string{"-where", "status!=200", "-where", "status<600"}, []string{`"status":503`, `"status":500`}},
+		{[]string{"-where", "message!~request"}, []string{`"health check failed"`, `"cache miss"`}},
+		{[]string{"-since", "1h"}, []string{`"status":200`, `"status":503`, `"status":500`, `"cache miss"`}},
+		{[]string{"-until", "1h"}, nil},
+		{[]string{"-since", "2000-01-01", "-until", "2100-01-01", "-level", "debug", "-where", "status"}, []string{`"status":200`, `"status":503`, `"status":500`}},
+	}
+
+	for _, c := range cases {
+		out := runTest(t, testInput(), append([]string{"-json"}, c.Args...)...)
+		var lines []string
+		if out != "" {
+			lines = strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		}
+		if len(lines) != len(c.Messages) {
+			t.Errorf("%v must select %d entries, got %d: %s", c.Args, len(c.Messages), len(lines), out)
+			continue
+		}
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "{") || !strings.Contains(line, c.Messages[i]) {
+				t.Errorf("%v selects wrong entry %d: %s", c.Args, i, line)
+			}
+		}
+	}
+}
+
+func TestRunTemplate(t *testing.T) {
+	out := runTest(t, testInput(), "-level", "warn", "-template", `{{.Level}} {{.Message}} status={{.Get "status"}}`)
+	if out != "warn request status=503\nerror health check failed status=500\n" {
+		t.Errorf("wrong template output: %q", out)
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(name, []byte(testInput()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runTest(t, `{"time":"2020-01-02T03:04:05Z","level":"error","message":"from stdin"}`+"\n", "-json", "-level", "error", name, "-")
+	if strings.Count(out, "\n") != 2 || !strings.Contains(out, "health check failed") || !strings.Contains(out, "from stdin") {
+		t.Errorf("wrong output of files: %s", out)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-level", "verbose"},
+		{"-since", "yesterday"},
+		{"-where", "=value"},
+		{"-where", "status>abc"},
+		{"-where", "path~("},
+		{"-color", "rainbow"},
+		{"-template", "{{.Time"},
+		{"-f"},
+		{"-f", "a.log", "b.log"},
+		{"-unknown"},
+		{filepath.Join(t.TempDir(), "missing.log")},
+	} {
+		var stdout, stderr bytes.Buffer
+		if err := run(args, strings.NewReader(""), &stdout, &stderr, nil); err == nil {
+			t.Errorf("run %v must return error", args)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		Value string
+		Time  time.Time
+	}{
+		{"", time.Time{}},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2019-12-31T00:00:00Z", time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"2019-12-31", time.Date(2019, 12, 31, 0, 0, 0, 0, time.Local)},
+		{"2019-12-31 08:00:00", time.Date(2019, 12, 31, 8, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		if ts, err := parseTime(c.Value, now); err != nil || !ts.Equal(c.Time) {
+			t.Errorf("parseTime(%q) want %v, got %v %v", c.Value, c.Time, ts, err)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestRunFollow(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("FileWriter symlinks require privileges on windows")
+	}
+
+	dir := t.TempDir()
+	w := &log.FileWriter{Filename: filepath.Join(dir, "app.log")}
+	defer w.Close()
+	logger := log.Logger{Writer: w}
+	logger.Info().Int("n", 1).Msg("before rotate")
+
+	var stdout, stderr syncBuffer
+	done := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- run([]string{"-f", "-json", w.Filename}, nil, &stdout, &stderr, done)
+	}()
+
+	wait := func(s string) {
+		t.Helper()
+		for i := 0; i < 100 && !strings.Contains(stdout.String(), s); i++ {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if !strings.Contains(stdout.String(), s) {
+			t.Fatalf("follow output must contain %s: %s", s, stdout.String())
+		}
+	}
+
+	wait(`"n":1`)
+	logger.Info().Int("n", 2).Msg("appended")
+	wait(`"n":2`)
+	time.Sleep(1100 * time.Millisecond)
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info().Int("n", 3).Msg("after rotate")
+	wait(`"n":3`)
+
+	close(done)
+	if err := <-result; err != nil {
+		t.Fatalf("follow error: %v", err)
+	}
+	if out := stdout.String(); strings.Count(out, "\n") != 3 {
+		t.Errorf("follow must print each entry once: %s", out)
+	}
+}
+
+func TestFollowerTruncate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(name, []byte("first line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	f, err := newFollower(name, 10*time.Millisecond, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, 64)
+	if n, _ := f.Read(buf); string(buf[:n]) != "first line\n" {
+		t.Fatalf("wrong first read: %q", buf[:n])
+	}
+	if err := os.WriteFile(name, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := f.Read(buf); string(buf[:n]) != "new\n" {
+		t.Fatalf("truncated file must be read from the beginning: %q", buf[:n])
+	}
+	close(done)
+	if n, err := f.Read(buf); n != 0 || err == nil {
+		t.Fatalf("read must return EOF after done: %d %v", n, err)
+	}
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"runtime"
@@ -51,7 +52,7 @@ func (w *ConsoleWriter) Close() (err error) {
 	return
 }
 
-func (w *ConsoleWriter) write(out io.Writer, p []byte) (int, error) {
+func (w *ConsoleWriter) write(out io.Writer, p []byte, names *FieldNames) (int, error) {
 	b := bbpool.Get().(*bb)
 	b.B = b.B[:0]
 	defer bbpool.Put(b)
@@ -59,7 +60,7 @@ func (w *ConsoleWriter) write(out io.Writer, p []byte) (int, error) {
 	b.B = append(b.B, p...)
 
 	var args FormatterArgs
-	parseFormatterArgs(b.B, &args)
+	parseFormatterArgs(b.B, &args, names)
 
 	switch {
 	case args.Time == "":
@@ -109,6 +110,14 @@ func (w *ConsoleWriter) format(out io.Writer, args *FormatterArgs) (n int, err e
 		color, three = Red, "PNC"
 	default:
 		color, three = Gray, "???"
+		if c := customLevelByName(args.Level); c != nil {
+			if c.Color != "" {
+				color = c.Color
+			}
+			if c.Abbr != "" {
+				three = c.Abbr
+			}
+		}
 	}
 
 	// pretty console writer
@@ -173,15 +182,41 @@ func (w *ConsoleWriter) format(out io.Writer, args *FormatterArgs) (n int, err e
 
 	// stack
 	if args.Stack != "" {
-		b.B = append(b.B, args.Stack...)
-		if args.Stack[len(args.Stack)-1] != '\n' {
-			b.B = append(b.B, '\n')
+		if args.Stack[0] == '[' {
+			b.B = appendStackFrames(b.B, args.Stack)
+		} else {
+			b.B = append(b.B, args.Stack...)
+			if args.Stack[len(args.Stack)-1] != '\n' {
+				b.B = append(b.B, '\n')
+			}
 		}
 	}
 
 	return out.Write(b.B)
 }
 
+// appendStackFrames renders the structured stack frames in the form of Go tracebacks.
+func appendStackFrames(dst []byte, stack string) []byte {
+	var frames []struct {
+		Func string `json:"func"`
+		File string `json:"file"`
+		Line int    `json:"line"`
+	}
+	if json.Unmarshal([]byte(stack), &frames) != nil {
+		dst = append(dst, stack...)
+		return append(dst, '\n')
+	}
+	for _, frame := range frames {
+		dst = append(dst, frame.Func...)
+		dst = append(dst, "\n\t"...)
+		dst = append(dst, frame.File...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, '\n')
+	}
+	return dst
+}
+
 type LogfmtFormatter struct {
 	TimeField string
 }
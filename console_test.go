@@ -1,11 +1,13 @@
 package log
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -92,6 +94,21 @@ func TestConsoleWriterNewline(t *testing.T) {
 	}
 }
 
+func TestConsoleWriterWrite(t *testing.T) {
+	var b1, b2 bytes.Buffer
+	line := `{"time":"2019-07-10T05:35:54.277Z","level":"warn","caller":"pretty.go:42","foo":"bar","n":42,"message":"hello console write"}` + "\n"
+
+	if _, err := (&ConsoleWriter{Writer: &b1}).Write([]byte(line)); err != nil {
+		t.Fatalf("console writer write error: %+v", err)
+	}
+	if _, err := (&ConsoleWriter{Writer: &b2}).WriteEntry(&Entry{buf: []byte(line)}); err != nil {
+		t.Fatalf("console writer write entry error: %+v", err)
+	}
+	if s := b1.String(); s != b2.String() || !strings.Contains(s, "WRN") || !strings.Contains(s, "hello console write") {
+		t.Fatalf("console writer write must format as write entry: %q, %q", s, b2.String())
+	}
+}
+
 func TestConsoleWriterQuote(t *testing.T) {
 	w := &ConsoleWriter{
 		ColorOutput: true,
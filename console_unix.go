@@ -64,5 +64,22 @@ func (w *ConsoleWriter) WriteEntry(e *Entry) (int, error) {
 	if out == nil {
 		out = os.Stderr
 	}
-	return w.write(out, e.buf)
+	if b := e.jsonbuf(); b != nil {
+		defer func() {
+			if cap(b.B) <= bbcap {
+				bbpool.Put(b)
+			}
+		}()
+		return w.write(out, b.B, e.names)
+	}
+	return w.write(out, e.buf, e.names)
+}
+
+// Write implements io.Writer, it formats the JSON log line p as WriteEntry does.
+func (w *ConsoleWriter) Write(p []byte) (int, error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+	return w.write(out, p, nil)
 }
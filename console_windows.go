@@ -32,6 +32,24 @@ var (
 
 // WriteEntry implements Writer
 func (w *ConsoleWriter) WriteEntry(e *Entry) (n int, err error) {
+	p := e.buf
+	if b := e.jsonbuf(); b != nil {
+		defer func() {
+			if cap(b.B) <= bbcap {
+				bbpool.Put(b)
+			}
+		}()
+		p = b.B
+	}
+	return w.writec(p, e.names)
+}
+
+// Write implements io.Writer, it formats the JSON log line p as WriteEntry does.
+func (w *ConsoleWriter) Write(p []byte) (n int, err error) {
+	return w.writec(p, nil)
+}
+
+func (w *ConsoleWriter) writec(p []byte, names *FieldNames) (n int, err error) {
 	onceConsole.Do(func() { isvt = isVirtualTerminal() })
 	muConsole.Lock()
 	defer muConsole.Unlock()
@@ -41,19 +59,19 @@ func (w *ConsoleWriter) WriteEntry(e *Entry) (n int, err error) {
 		out = os.Stderr
 	}
 	if isvt {
-		n, err = w.write(out, e.buf)
+		n, err = w.write(out, p, names)
 	} else {
-		n, err = w.writew(out, e.buf)
+		n, err = w.writew(out, p, names)
 	}
 	return
 }
 
-func (w *ConsoleWriter) writew(out io.Writer, p []byte) (n int, err error) {
+func (w *ConsoleWriter) writew(out io.Writer, p []byte, names *FieldNames) (n int, err error) {
 	b := bbpool.Get().(*bb)
 	b.B = b.B[:0]
 	defer bbpool.Put(b)
 
-	n, err = w.write(b, p)
+	n, err = w.write(b, p, names)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"context"
+)
+
+type loggerContextKey struct{}
+
+type fieldsContextKey struct{}
+
+// ContextExtractor extracts values from ctx and adds them as fields to the entry.
+type ContextExtractor func(ctx context.Context, e *Entry)
+
+// WithLogger returns a copy of ctx in which the logger is carried.
+func WithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or &DefaultLogger if none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return &DefaultLogger
+}
+
+// WithFields returns a copy of ctx in which the contextual fields are carried,
+// the fields are appended to the fields already carried by ctx.
+func WithFields(ctx context.Context, fields Context) context.Context {
+	if parent := FieldsFromContext(ctx); len(parent) != 0 {
+		fields = append(parent[:len(parent):len(parent)], fields...)
+	}
+	return context.WithValue(ctx, fieldsContextKey{}, fields)
+}
+
+// FieldsFromContext returns the contextual fields carried by ctx.
+func FieldsFromContext(ctx context.Context) Context {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).(Context)
+	return fields
+}
+
+// Ctx adds the contextual fields carried by ctx and the fields of context extractors to the entry.
+// The contextual fields are redacted by the Redactor of the logger when they are added.
+func (e *Entry) Ctx(ctx context.Context) *Entry {
+	if e == nil || ctx == nil {
+		return e
+	}
+
+	if fields, _ := ctx.Value(fieldsContextKey{}).(Context); len(fields) != 0 {
+		n := len(e.buf)
+		e.buf = append(e.buf, fields...)
+		if e.redactor != nil {
+			e.buf = e.redactor.redactFields(e.buf, n, "")
+		}
+	}
+	for _, extract := range e.extractors {
+		extract(ctx, e)
+	}
+	return e
+}
+
+// TraceContext starts a new message with trace level and the fields of ctx.
+func (l *Logger) TraceContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, TraceLevel)
+}
+
+// DebugContext starts a new message with debug level and the fields of ctx.
+func (l *Logger) DebugContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, DebugLevel)
+}
+
+// InfoContext starts a new message with info level and the fields of ctx.
+func (l *Logger) InfoContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, InfoLevel)
+}
+
+// WarnContext starts a new message with warning level and the fields of ctx.
+func (l *Logger) WarnContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, WarnLevel)
+}
+
+// ErrorContext starts a new message with error level and the fields of ctx.
+func (l *Logger) ErrorContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, ErrorLevel)
+}
+
+// FatalContext starts a new message with fatal level and the fields of ctx.
+func (l *Logger) FatalContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, FatalLevel)
+}
+
+// PanicContext starts a new message with panic level and the fields of ctx.
+func (l *Logger) PanicContext(ctx context.Context) (e *Entry) {
+	return l.context(ctx, PanicLevel)
+}
+
+// WithLevelContext starts a new message with level and the fields of ctx.
+func (l *Logger) WithLevelContext(ctx context.Context, level Level) (e *Entry) {
+	return l.context(ctx, level)
+}
+
+// context starts a new message with level and the fields of ctx, it must be
+// called directly by the exported methods to report the right caller.
+func (l *Logger) context(ctx context.Context, level Level) (e *Entry) {
+	if l.silent(level) || l.sampleout(level) {
+		return nil
+	}
+	e = l.header(level)
+	if caller, full := l.Caller, false; caller != 0 {
+		if caller < 0 {
+			caller, full = -caller, true
+		}
+		var pc uintptr
+		e.caller(caller1(caller+1, &pc, 1, 1), pc, full)
+	}
+	return e.Ctx(ctx)
+}
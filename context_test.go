@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type testRequestIDKey struct{}
+
+func TestLoggerContextCarry(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+		ContextExtractors: []ContextExtractor{
+			func(ctx context.Context, e *Entry) {
+				if id, ok := ctx.Value(testRequestIDKey{}).(string); ok {
+					e.Str("request_id", id)
+				}
+			},
+		},
+	}
+
+	if FromContext(context.Background()) != &DefaultLogger {
+		t.Fatal("FromContext must return DefaultLogger if ctx carries no logger")
+	}
+
+	ctx := WithLogger(context.Background(), &logger)
+	ctx = context.WithValue(ctx, testRequestIDKey{}, "abc")
+	ctx = WithFields(ctx, NewContext(nil).Str("user", "alice").Value())
+	ctx = WithFields(ctx, NewContext(nil).Int("tenant", 42).Value())
+
+	if FromContext(ctx) != &logger {
+		t.Fatal("FromContext must return the carried logger")
+	}
+
+	FromContext(ctx).InfoContext(ctx).Str("foo", "bar").Msg("hello ctx")
+	if s := b.String(); !strings.Contains(s, `"level":"info","user":"alice","tenant":42,"request_id":"abc","foo":"bar","message":"hello ctx"}`) {
+		t.Fatalf("context fields missing: %s", s)
+	}
+
+	b.Reset()
+	logger.DebugContext(ctx).Msg("hello debug ctx")
+	if b.Len() != 0 {
+		t.Fatalf("DebugContext must respect level: %s", b.String())
+	}
+
+	b.Reset()
+	logger.Info().Ctx(nil).Ctx(context.Background()).Msg("hello nil ctx")
+	if s := b.String(); strings.Contains(s, "request_id") || strings.Contains(s, "alice") {
+		t.Fatalf("empty ctx must not add fields: %s", s)
+	}
+
+	b.Reset()
+	logger.WithLevelContext(ctx, WarnLevel).Msg("hello warn ctx")
+	if s := b.String(); !strings.Contains(s, `"level":"warn","user":"alice"`) {
+		t.Fatalf("WithLevelContext must add context fields: %s", s)
+	}
+}
+
+func TestLoggerContextCaller(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  TraceLevel,
+		Caller: 1,
+		Writer: &IOWriter{Writer: &b},
+	}
+
+	ctx := context.Background()
+	for _, e := range []*Entry{
+		logger.TraceContext(ctx),
+		logger.DebugContext(ctx),
+		logger.InfoContext(ctx),
+		logger.WarnContext(ctx),
+		logger.ErrorContext(ctx),
+		logger.WithLevelContext(ctx, InfoLevel),
+	} {
+		b.Reset()
+		e.Msg("hello caller ctx")
+		if s := b.String(); !strings.Contains(s, `context_test.go:`) {
+			t.Fatalf("caller must be the caller of the context method: %s", s)
+		}
+	}
+}
+
+func TestLoggerContextRedact(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"password", "user.token"}},
+	}
+
+	ctx := WithFields(context.Background(), NewContext(nil).Str("password", "secret").Dict("user", NewContext(nil).Str("name", "alice").Str("token", "abc").Value()).Value())
+
+	logger.InfoContext(ctx).Msg("hello redact ctx")
+	if s := b.String(); !strings.Contains(s, `"password":"[REDACTED]","user":{"name":"alice","token":"[REDACTED]"},"message":"hello redact ctx"}`) {
+		t.Fatalf("context fields must be redacted: %s", s)
+	}
+}
@@ -0,0 +1,428 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decoder reads the JSON log entries line by line from an input stream, it tolerates
+// the corrupt and partial lines, which are returned as invalid records.
+//
+//	d := log.NewDecoder(os.Stdin)
+//	for d.Next() {
+//		r := d.Record()
+//		id, _ := r.Get("user.id")
+//		fmt.Println(r.Level(), r.Message(), id.String())
+//	}
+//	if err := d.Err(); err != nil { ... }
+type Decoder struct {
+	// FieldNames specifies the optional names of builtin fields.
+	FieldNames *FieldNames
+
+	// TimeFormat specifies the format of time field, see Logger.TimeFormat.
+	TimeFormat string
+
+	// MaxLineSize specifies the maximum size of lines, the longer lines are truncated.
+	// It uses 1MB if empty.
+	MaxLineSize int
+
+	r      *bufio.Reader
+	line   []byte
+	record Record
+	err    error
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReaderSize(r, 32*1024)}
+}
+
+// Next reads the next non-empty line, it returns false at the end of input or on a read error.
+// After Next returns false at the end of input, it can be called again if r is appended.
+func (d *Decoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	maxsize := d.MaxLineSize
+	if maxsize <= 0 {
+		maxsize = 1024 * 1024
+	}
+	for {
+		d.line = d.line[:0]
+		var err error
+		for {
+			var b []byte
+			b, err = d.r.ReadSlice('\n')
+			if len(d.line)+len(b) > maxsize {
+				b = b[:maxsize-len(d.line)]
+			}
+			d.line = append(d.line, b...)
+			if err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		line := bytes.TrimSpace(d.line)
+		if len(line) != 0 {
+			d.record.FieldNames, d.record.TimeFormat = d.FieldNames, d.TimeFormat
+			d.record.Parse(line)
+			if err != nil && err != io.EOF {
+				d.err = err
+			}
+			return true
+		}
+		if err != nil {
+			if err != io.EOF {
+				d.err = err
+			}
+			return false
+		}
+	}
+}
+
+// Record returns the record read by Next, it is only valid until the next call of Next.
+func (d *Decoder) Record() *Record {
+	return &d.record
+}
+
+// Err returns the first non-EOF error of reading.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Record is a parsed JSON log entry. The bytes returned by its methods refer to
+// the parsed line, they are only valid until the line is changed or reused.
+type Record struct {
+	// FieldNames specifies the optional names of builtin fields.
+	FieldNames *FieldNames
+
+	// TimeFormat specifies the format of time field, see Logger.TimeFormat.
+	TimeFormat string
+
+	raw    []byte
+	valid  bool
+	fields []recordField
+	// the indices plus one of time, level, caller, callerfunc, goid, stack and message fields.
+	builtins [7]int
+}
+
+type recordField struct {
+	key   []byte
+	value RecordValue
+}
+
+// Parse parses line into the record, it reports whether line is a complete JSON object.
+// The fields before the corruption of an invalid line are still parsed.
+func (r *Record) Parse(line []byte) bool {
+	r.raw = line
+	r.valid = false
+	r.fields = r.fields[:0]
+	r.builtins = [7]int{}
+
+	i := recordSkipSpace(line, 0)
+	if i >= len(line) || line[i] != '{' {
+		return false
+	}
+	for i++; ; {
+		i = recordSkipSpace(line, i)
+		if i < len(line) && line[i] == ',' && len(r.fields) != 0 {
+			i = recordSkipSpace(line, i+1)
+		} else if i < len(line) && line[i] == '}' {
+			r.valid = recordSkipSpace(line, i+1) == len(line)
+			return r.valid
+		}
+		var key []byte
+		var value RecordValue
+		var ok bool
+		if i, key, value, ok = recordParseField(line, i); !ok {
+			return false
+		}
+		r.fields = append(r.fields, recordField{key, value})
+		if pos := formatterArgsPos(b2s(key), r.FieldNames); pos != 0 && r.builtins[pos-1] == 0 {
+			r.builtins[pos-1] = len(r.fields)
+		}
+	}
+}
+
+// Raw returns the raw line of record.
+func (r *Record) Raw() []byte {
+	return r.raw
+}
+
+// Valid reports whether the line of record is a complete JSON object.
+func (r *Record) Valid() bool {
+	return r.valid
+}
+
+func (r *Record) builtin(pos int) RecordValue {
+	if i := r.builtins[pos-1]; i != 0 {
+		return r.fields[i-1].value
+	}
+	return nil
+}
+
+// Time returns the time field of record, it recognizes RFC3339, the UNIX timestamps
+// and TimeFormat of record. It reports false if the time field is absent or malformed.
+func (r *Record) Time() (time.Time, bool) {
+	v := r.builtin(1)
+	switch v.Type() {
+	case 's':
+		layout := r.TimeFormat
+		if layout == "" || layout == TimeFormatUnix || layout == TimeFormatUnixMs || layout == TimeFormatUnixWithMs {
+			layout = time.RFC3339Nano
+		}
+		t, err := time.Parse(layout, v.String())
+		return t, err == nil
+	case 'n':
+		s := b2s(v)
+		sec, frac, dot := strings.Cut(s, ".")
+		n, err := strconv.ParseInt(sec, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if !dot {
+			if r.TimeFormat == TimeFormatUnixMs || (r.TimeFormat != TimeFormatUnix && len(sec) >= 12) {
+				return time.UnixMilli(n), true
+			}
+			return time.Unix(n, 0), true
+		}
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nsec, err := strconv.ParseInt(frac+"000000000"[len(frac):], 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, nsec), true
+	}
+	return time.Time{}, false
+}
+
+// Level returns the level field of record, or 0 if it is absent or unknown.
+func (r *Record) Level() Level {
+	if level, ok := LookupLevel(r.builtin(2).String()); ok {
+		return level
+	}
+	return 0
+}
+
+// Caller returns the caller field of record.
+func (r *Record) Caller() string {
+	return r.builtin(3).String()
+}
+
+// Message returns the message field of record.
+func (r *Record) Message() string {
+	return r.builtin(7).String()
+}
+
+// Get returns the value of field key, a dotted key such as "user.name" looks up
+// the nested objects. The last one is returned if the key is duplicated.
+func (r *Record) Get(key string) (RecordValue, bool) {
+	for i := len(r.fields) - 1; i >= 0; i-- {
+		if value, ok := recordLookup(recordKey(r.fields[i].key), r.fields[i].value, key); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Range calls fn sequentially for each field of record, including the builtin fields.
+// If fn returns false, Range stops the iteration.
+func (r *Record) Range(fn func(key string, value RecordValue) bool) {
+	for _, f := range r.fields {
+		if !fn(recordKey(f.key), f.value) {
+			return
+		}
+	}
+}
+
+// IsBuiltin reports whether key is the name of a builtin field, such as time, level and message.
+func (r *Record) IsBuiltin(key string) bool {
+	return formatterArgsPos(key, r.FieldNames) != 0
+}
+
+// RecordValue is a raw JSON value of record.
+type RecordValue []byte
+
+// Type returns the type of value, 's' for strings, 'n' for numbers, 'o' for objects,
+// 'a' for arrays, 't' for true, 'f' for false, and 0 for null or empty values.
+func (v RecordValue) Type() byte {
+	if len(v) == 0 {
+		return 0
+	}
+	switch c := v[0]; c {
+	case '"':
+		return 's'
+	case '{':
+		return 'o'
+	case '[':
+		return 'a'
+	case 't', 'f':
+		return c
+	case 'n':
+		return 0
+	}
+	return 'n'
+}
+
+// String returns the unescaped content of strings, or the raw JSON of other values.
+// It allocates only if the string contains escapes.
+func (v RecordValue) String() string {
+	if len(v) < 2 || v[0] != '"' {
+		return b2s(v)
+	}
+	s := v[1 : len(v)-1]
+	if !jsonHasEscape(s) {
+		return b2s(s)
+	}
+	return string(jsonUnescape(s, make([]byte, 0, len(s))))
+}
+
+// Int returns the value of integer numbers, or of strings quoting integers.
+func (v RecordValue) Int() (int64, bool) {
+	if len(v) >= 2 && v[0] == '"' {
+		v = v[1 : len(v)-1]
+	}
+	n, err := strconv.ParseInt(b2s(v), 10, 64)
+	return n, err == nil
+}
+
+// Float returns the value of numbers, or of strings quoting numbers.
+func (v RecordValue) Float() (float64, bool) {
+	if len(v) >= 2 && v[0] == '"' {
+		v = v[1 : len(v)-1]
+	}
+	f, err := strconv.ParseFloat(b2s(v), 64)
+	return f, err == nil
+}
+
+// Bool returns the value of booleans.
+func (v RecordValue) Bool() (bool, bool) {
+	switch b2s(v) {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	return false, false
+}
+
+// IsNull reports whether the value is null.
+func (v RecordValue) IsNull() bool {
+	return b2s(v) == "null"
+}
+
+// Get returns the value of field key in the object, a dotted key looks up the nested objects.
+func (v RecordValue) Get(key string) (RecordValue, bool) {
+	if v.Type() != 'o' {
+		return nil, false
+	}
+	var result RecordValue
+	var found bool
+	v.Range(func(k string, value RecordValue) bool {
+		if value, ok := recordLookup(k, value, key); ok {
+			result, found = value, true
+		}
+		return true
+	})
+	return result, found
+}
+
+// recordLookup returns value if k is key, or the nested value of dotted key which starts with k.
+func recordLookup(k string, value RecordValue, key string) (RecordValue, bool) {
+	if k == key {
+		return value, true
+	}
+	if len(key) > len(k) && key[len(k)] == '.' && key[:len(k)] == k {
+		return value.Get(key[len(k)+1:])
+	}
+	return nil, false
+}
+
+// Range calls fn sequentially for each field of the object, or each element of the array
+// with empty keys. If fn returns false, Range stops the iteration.
+func (v RecordValue) Range(fn func(key string, value RecordValue) bool) {
+	typ := v.Type()
+	if typ != 'o' && typ != 'a' {
+		return
+	}
+	for i := 1; ; {
+		i = recordSkipSpace(v, i)
+		if i < len(v) && v[i] == ',' {
+			i = recordSkipSpace(v, i+1)
+		}
+		if i >= len(v) || v[i] == '}' || v[i] == ']' {
+			return
+		}
+		var key []byte
+		var value RecordValue
+		var ok bool
+		if typ == 'o' {
+			i, key, value, ok = recordParseField(v, i)
+		} else {
+			i, value, ok = recordParseValue(v, i)
+		}
+		if !ok || !fn(recordKey(key), value) {
+			return
+		}
+	}
+}
+
+// recordParseField parses the `"key":value` at position i of b.
+func recordParseField(b []byte, i int) (int, []byte, RecordValue, bool) {
+	if i >= len(b) || b[i] != '"' {
+		return i, nil, nil, false
+	}
+	i, key, _, ok := jsonParseString(b, i+1)
+	if !ok {
+		return i, nil, nil, false
+	}
+	key = key[1 : len(key)-1]
+	if i = recordSkipSpace(b, i); i >= len(b) || b[i] != ':' {
+		return i, nil, nil, false
+	}
+	i, value, ok := recordParseValue(b, i+1)
+	return i, key, value, ok
+}
+
+// recordParseValue parses the JSON value at position i of b.
+func recordParseValue(b []byte, i int) (int, RecordValue, bool) {
+	if i = recordSkipSpace(b, i); i >= len(b) {
+		return i, nil, false
+	}
+	switch b[i] {
+	case '"', '{', '[', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 't', 'f', 'n':
+	default:
+		return i, nil, false
+	}
+	i, typ, value, ok := jsonParseAny(b, i, true)
+	if !ok {
+		return i, nil, false
+	}
+	if typ == 'o' {
+		// the squashed objects and arrays are not closed if truncated
+		if len(value) < 2 || (value[len(value)-1] != '}' && value[len(value)-1] != ']') {
+			return i, nil, false
+		}
+	}
+	return i, value, true
+}
+
+func recordSkipSpace(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\r' || b[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// recordKey returns the unescaped key.
+func recordKey(key []byte) string {
+	if !jsonHasEscape(key) {
+		return b2s(key)
+	}
+	return string(jsonUnescape(key, make([]byte, 0, len(key))))
+}
@@ -0,0 +1,250 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoder(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  TraceLevel,
+		Caller: 1,
+		Writer: &IOWriter{Writer: &b},
+	}
+	logger.Info().Str("foo", "bar").Int("n", 42).Dict("user", NewContext(nil).Int("id", 7).Str("name", "a \"quoted\" name").Value()).Msg("hello")
+	b.WriteString("\n   \n")
+	b.WriteString("not a json line\n")
+	logger.Error().Strs("tags", []string{"x", "y"}).Bool("ok", false).Msg("line\nbreak")
+	b.WriteString(`{"time":"2020-01-02T03:04:05Z","level":"warn","message":"partial","obj":{"a":1`)
+
+	d := NewDecoder(&b)
+	var records []string
+	for d.Next() {
+		r := d.Record()
+		switch len(records) {
+		case 0:
+			if !r.Valid() || r.Level() != InfoLevel || r.Message() != "hello" || !strings.Contains(r.Caller(), "decoder_test.go:") {
+				t.Errorf("wrong builtin fields of record: %s", r.Raw())
+			}
+			if ts, ok := r.Time(); !ok || time.Since(ts) > time.Minute {
+				t.Errorf("wrong time of record: %v %v", ts, ok)
+			}
+			if v, ok := r.Get("foo"); !ok || v.Type() != 's' || v.String() != "bar" {
+				t.Errorf("wrong foo field: %s", v)
+			}
+			if v, ok := r.Get("n"); !ok || v.Type() != 'n' {
+				t.Errorf("wrong n field: %s", v)
+			} else if n, ok := v.Int(); !ok || n != 42 {
+				t.Errorf("wrong n value: %d", n)
+			}
+			if v, ok := r.Get("user.id"); !ok || string(v) != "7" {
+				t.Errorf("wrong user.id field: %s", v)
+			}
+			if v, ok := r.Get("user.name"); !ok || v.String() != `a "quoted" name` {
+				t.Errorf("wrong user.name field: %s", v)
+			}
+			if _, ok := r.Get("user.missing"); ok {
+				t.Errorf("missing field must not be found")
+			}
+		case 1:
+			if r.Valid() || r.Message() != "" || r.Level() != 0 {
+				t.Errorf("corrupt line must be an invalid record: %s", r.Raw())
+			}
+		case 2:
+			if !r.Valid() || r.Level() != ErrorLevel || r.Message() != "line\nbreak" {
+				t.Errorf("wrong record: %s", r.Raw())
+			}
+			var elems []string
+			v, _ := r.Get("tags")
+			v.Range(func(key string, value RecordValue) bool {
+				elems = append(elems, value.String())
+				return true
+			})
+			if v.Type() != 'a' || strings.Join(elems, ",") != "x,y" {
+				t.Errorf("wrong tags field: %s", v)
+			}
+			if v, _ := r.Get("ok"); v.Type() != 'f' {
+				t.Errorf("wrong ok field: %s", v)
+			} else if ok, valid := v.Bool(); ok || !valid {
+				t.Errorf("wrong ok value: %v", ok)
+			}
+		case 3:
+			if r.Valid() || r.Level() != WarnLevel || r.Message() != "partial" {
+				t.Errorf("partial line must be an invalid record with parsed fields: %s", r.Raw())
+			}
+			if _, ok := r.Get("obj"); ok {
+				t.Errorf("truncated field must not be found")
+			}
+		}
+		records = append(records, string(d.Record().Raw()))
+	}
+	if d.Err() != nil || len(records) != 4 {
+		t.Fatalf("decoder must read 4 records, got %d: %v", len(records), d.Err())
+	}
+}
+
+func TestDecoderFollow(t *testing.T) {
+	r, w := io.Pipe()
+	d := NewDecoder(r)
+	go func() {
+		_, _ = io.WriteString(w, `{"level":"info","message":"one"}`+"\n")
+		_ = w.CloseWithError(io.ErrUnexpectedEOF)
+	}()
+
+	if !d.Next() || d.Record().Message() != "one" {
+		t.Fatalf("decoder must read the first record")
+	}
+	if d.Next() || d.Err() != io.ErrUnexpectedEOF {
+		t.Fatalf("decoder must report read error: %v", d.Err())
+	}
+
+	var b bytes.Buffer
+	d = NewDecoder(&b)
+	if d.Next() {
+		t.Fatalf("decoder must stop at the end of input")
+	}
+	b.WriteString(`{"message":"appended"}` + "\n")
+	if !d.Next() || d.Record().Message() != "appended" {
+		t.Fatalf("decoder must continue after the input is appended")
+	}
+}
+
+func TestDecoderMaxLineSize(t *testing.T) {
+	line := `{"message":"` + strings.Repeat("x", 100000) + `"}`
+	d := NewDecoder(strings.NewReader(line + "\n" + `{"message":"next"}` + "\n"))
+	d.MaxLineSize = 50000
+
+	if !d.Next() || d.Record().Valid() || len(d.Record().Raw()) != 50000 {
+		t.Fatalf("long line must be truncated to an invalid record: %d", len(d.Record().Raw()))
+	}
+	if !d.Next() || d.Record().Message() != "next" {
+		t.Fatalf("decoder must read the line after a truncated line")
+	}
+}
+
+func TestRecordTime(t *testing.T) {
+	cases := []struct {
+		Line       string
+		TimeFormat string
+		Time       time.Time
+	}{
+		{`{"time":"2020-01-02T03:04:05.678Z"}`, "", time.Date(2020, 1, 2, 3, 4, 5, 678000000, time.UTC)},
+		{`{"time":1577934245}`, "", time.Unix(1577934245, 0)},
+		{`{"time":1577934245678}`, "", time.UnixMilli(1577934245678)},
+		{`{"time":1577934245.678}`, "", time.Unix(1577934245, 678000000)},
+		{`{"time":1577934245678}`, TimeFormatUnix, time.Unix(1577934245678, 0)},
+		{`{"time":"02 Jan 20 03:04 UTC"}`, time.RFC822, time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC)},
+		{`{"ts":"2020-01-02T03:04:05Z"}`, "", time.Time{}},
+		{`{"time":"yesterday"}`, "", time.Time{}},
+	}
+
+	var r Record
+	for _, c := range cases {
+		r.TimeFormat = c.TimeFormat
+		r.Parse([]byte(c.Line))
+		if ts, ok := r.Time(); !ts.Equal(c.Time) || ok == c.Time.IsZero() {
+			t.Errorf("time of %s want %v, got %v %v", c.Line, c.Time, ts, ok)
+		}
+	}
+}
+
+func TestRecordFieldNames(t *testing.T) {
+	r := Record{FieldNames: &FieldNames{Time: "ts", Level: "severity", Message: "text"}}
+	r.Parse([]byte(`{"ts":1577934245,"severity":"ERROR","text":"custom","message":"other"}`))
+
+	if r.Level() != ErrorLevel || r.Message() != "custom" || !r.IsBuiltin("severity") || r.IsBuiltin("other") {
+		t.Errorf("record must respect field names: %s", r.Raw())
+	}
+	if ts, ok := r.Time(); !ok || ts.Unix() != 1577934245 {
+		t.Errorf("wrong time of record: %v", ts)
+	}
+
+	var keys []string
+	r.Range(func(key string, value RecordValue) bool {
+		keys = append(keys, key)
+		return key != "text"
+	})
+	if strings.Join(keys, ",") != "ts,severity,text" {
+		t.Errorf("Range must stop when fn returns false: %v", keys)
+	}
+}
+
+func TestRecordValue(t *testing.T) {
+	var r Record
+	if !r.Parse([]byte(` { "a\u0062" : "\u4e2d" , "n": -1.5e3, "s": "12", "null": null, "t": true, "o": {"x.y": {"z": [1, {"k": "v"}]}} } `)) {
+		t.Fatalf("record must be valid: %s", r.Raw())
+	}
+
+	if v, ok := r.Get("ab"); !ok || v.String() != "中" {
+		t.Errorf("escaped key and value must be unescaped: %s", v)
+	}
+	if v, _ := r.Get("n"); v.Type() != 'n' {
+		t.Errorf("wrong type of number: %c", v.Type())
+	} else if f, ok := v.Float(); !ok || f != -1500 {
+		t.Errorf("wrong float value: %v", f)
+	}
+	if v, _ := r.Get("s"); v.Type() != 's' {
+		t.Errorf("wrong type of string: %c", v.Type())
+	} else if n, ok := v.Int(); !ok || n != 12 {
+		t.Errorf("quoted integer must be parsed: %v", n)
+	}
+	if v, ok := r.Get("null"); !ok || !v.IsNull() || v.Type() != 0 {
+		t.Errorf("wrong null value: %s", v)
+	}
+	if v, _ := r.Get("t"); v.Type() != 't' {
+		t.Errorf("wrong true value: %s", v)
+	}
+	if v, ok := r.Get("o.x.y.z"); !ok || v.Type() != 'a' {
+		t.Errorf("dotted key must be found in nested objects: %s", v)
+	}
+	if _, ok := r.Get("o.x"); ok {
+		t.Errorf("partial dotted key must not be found")
+	}
+}
+
+func TestRecordCorrupt(t *testing.T) {
+	lines := []string{
+		``,
+		`[1,2]`,
+		`{`,
+		`{"a"`,
+		`{"a":`,
+		`{"a":1,`,
+		`{"a":"unterminated}`,
+		`{"a":xyz}`,
+		`{"a":1} trailing`,
+		`{"a":[1,2}`,
+	}
+
+	var r Record
+	for _, line := range lines {
+		if r.Parse([]byte(line)) || r.Valid() {
+			t.Errorf("corrupt line must be invalid: %s", line)
+		}
+	}
+	if r.Parse([]byte(`{}`)); !r.Valid() {
+		t.Errorf("empty object must be valid")
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	line := `{"time":"2019-07-10T05:35:54.277Z","level":"info","caller":"prog.go:42","foo":"bar","n":42,"user":{"id":7},"message":"hello json console color writer"}` + "\n"
+	data := []byte(strings.Repeat(line, 1000))
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		for d.Next() {
+			r := d.Record()
+			_ = r.Level()
+			_ = r.Message()
+			_, _ = r.Get("user.id")
+		}
+	}
+}
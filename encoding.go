@@ -0,0 +1,237 @@
+package log
+
+import (
+	"strconv"
+)
+
+// Encoding defines the wire format of entries written by a Logger.
+type Encoding uint8
+
+const (
+	// JSONEncoding writes entries as JSON lines, it is the default encoding.
+	JSONEncoding Encoding = iota
+	// CBOREncoding writes entries as a sequence of CBOR (RFC 8949) maps,
+	// which can be converted back to JSON lines by CBORToJSON or CBORReader.
+	CBOREncoding
+	// LogfmtEncoding writes entries as logfmt lines, the keys of nested objects
+	// are flattened with dots and the arrays are written as quoted JSON.
+	LogfmtEncoding
+)
+
+// String returns the name of encoding.
+func (enc Encoding) String() string {
+	switch enc {
+	case JSONEncoding:
+		return "json"
+	case CBOREncoding:
+		return "cbor"
+	case LogfmtEncoding:
+		return "logfmt"
+	}
+	return ""
+}
+
+// The Entry methods of frequently used types write fields in the encoding of
+// entry directly, the others write JSON fields which are pending after
+// e.encoded and transcoded by e.encode before the next encoded field.
+
+// encode transcodes the pending JSON fields of entry to its encoding.
+func (e *Entry) encode() {
+	if e.encoded == len(e.buf) {
+		return
+	}
+	b := bbpool.Get().(*bb)
+	b.B = append(b.B[:0], e.buf[e.encoded:]...)
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendJSONFields(e.buf[:e.encoded], b.B)
+	case LogfmtEncoding:
+		var tmp [64]byte
+		e.buf = logfmtAppendJSONFields(e.buf[:e.encoded], b.B, tmp[:0])
+	}
+	e.encoded = len(e.buf)
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+}
+
+// encodeHeader encodes the JSON time field of header which value starts at n, and adds the level field.
+func (e *Entry) encodeHeader(n int, level Level) {
+	var tmp [64]byte
+	b := append(tmp[:0], e.buf...)
+	key, val := b[2:n-2], b[n:]
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = append(e.buf[:0], cborMap|cborIndefinite)
+		e.buf = cborAppendText(e.buf, key)
+		if len(val) >= 2 && val[0] == '"' {
+			e.buf = cborAppendText(e.buf, val[1:len(val)-1])
+		} else {
+			e.buf, _ = cborAppendJSON(e.buf, val)
+		}
+		if TraceLevel <= level && level <= PanicLevel || customLevel(level) != nil {
+			e.buf = cborAppendString(e.buf, e.names.level())
+			e.buf = cborAppendString(e.buf, level.String())
+		}
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf[:0], b2s(key))
+		if len(val) >= 2 && val[0] == '"' {
+			e.buf = logfmtAppendValue(e.buf, b2s(val[1:len(val)-1]))
+		} else {
+			e.buf = append(e.buf, val...)
+		}
+		if TraceLevel <= level && level <= PanicLevel || customLevel(level) != nil {
+			e.buf = logfmtAppendKey(e.buf, e.names.level())
+			e.buf = logfmtAppendValue(e.buf, level.String())
+		}
+	}
+	e.encoded = len(e.buf)
+}
+
+func (e *Entry) encodeStr(key string, val string) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendString(e.buf, val)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = logfmtAppendValue(e.buf, val)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeBytes(key string, val []byte) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendText(e.buf, val)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = logfmtAppendValue(e.buf, b2s(val))
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeBinary(key string, val []byte) *Entry {
+	if e.enc != CBOREncoding {
+		return e.encodeBytes(key, val)
+	}
+	e.encode()
+	e.buf = cborAppendString(e.buf, key)
+	e.buf = cborAppendBytes(e.buf, val)
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeInt(key string, i int64) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendInt(e.buf, i)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = strconv.AppendInt(e.buf, i, 10)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeUint(key string, i uint64) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendUint(e.buf, i)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = strconv.AppendUint(e.buf, i, 10)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeFloat(key string, f float64, bits int) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendFloat(e.buf, f)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = logfmtAppendFloat(e.buf, f, bits)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeBool(key string, b bool) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = cborAppendBool(e.buf, b)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = strconv.AppendBool(e.buf, b)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+func (e *Entry) encodeNull(key string) *Entry {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		e.buf = cborAppendString(e.buf, key)
+		e.buf = append(e.buf, cborNull)
+	case LogfmtEncoding:
+		e.buf = logfmtAppendKey(e.buf, key)
+		e.buf = append(e.buf, "null"...)
+	}
+	e.encoded = len(e.buf)
+	return e
+}
+
+// encodeMsg appends the message field if not empty and ends the entry.
+func (e *Entry) encodeMsg(msg string) {
+	e.encode()
+	switch e.enc {
+	case CBOREncoding:
+		if msg != "" {
+			e.buf = cborAppendString(e.buf, e.names.message())
+			e.buf = cborAppendString(e.buf, msg)
+		}
+		e.buf = append(e.buf, cborBreak)
+	case LogfmtEncoding:
+		if msg != "" {
+			e.buf = logfmtAppendKey(e.buf, e.names.message())
+			e.buf = logfmtAppendValue(e.buf, msg)
+		}
+		e.buf = append(e.buf, '\n')
+	}
+	e.encoded = len(e.buf)
+}
+
+// jsonbuf returns the JSON line of entry in a pooled buffer for the writers
+// parsing JSON, or nil if the entry is JSON encoded.
+func (e *Entry) jsonbuf() *bb {
+	if e.enc == JSONEncoding {
+		return nil
+	}
+	b := bbpool.Get().(*bb)
+	b.B = b.B[:0]
+	switch e.enc {
+	case CBOREncoding:
+		b.B, _, _ = CBORToJSON(b.B, e.buf)
+		b.B = append(b.B, '\n')
+	case LogfmtEncoding:
+		b.B = logfmtToJSON(b.B, e.buf)
+		b.B = append(b.B, '\n')
+	}
+	return b
+}
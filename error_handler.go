@@ -0,0 +1,101 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorHandler defines an interface to handle the errors of writing entries.
+type ErrorHandler interface {
+	// HandleError handles err returned by the Writer of logger when writing the entry.
+	// The entry is only valid during the call.
+	HandleError(e *Entry, err error)
+}
+
+// The ErrorHandlerFunc type is an adapter to allow the use of
+// ordinary functions as error handlers. If f is a function
+// with the appropriate signature, ErrorHandlerFunc(f) is a
+// [ErrorHandler] that calls f.
+type ErrorHandlerFunc func(e *Entry, err error)
+
+// HandleError calls f(e, err).
+func (f ErrorHandlerFunc) HandleError(e *Entry, err error) {
+	f(e, err)
+}
+
+// DefaultErrorHandler is the ErrorHandler used by loggers without an ErrorHandler.
+var DefaultErrorHandler ErrorHandler = &RateLimitErrorHandler{}
+
+// RateLimitErrorHandler is an ErrorHandler that counts the errors and reports
+// at most one error per Interval, the others are counted as suppressed.
+type RateLimitErrorHandler struct {
+	// Interval specifies the minimum interval of reports, ten seconds if empty.
+	Interval time.Duration
+
+	// Writer specifies the writer of reports. It uses os.Stderr if empty.
+	Writer io.Writer
+
+	errors     uint64
+	suppressed uint64
+	resetAt    int64
+}
+
+// HandleError implements ErrorHandler.
+func (h *RateLimitErrorHandler) HandleError(e *Entry, err error) {
+	atomic.AddUint64(&h.errors, 1)
+
+	interval := h.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	now := timeNow().UnixNano()
+	resetAt := atomic.LoadInt64(&h.resetAt)
+	if now <= resetAt || !atomic.CompareAndSwapInt64(&h.resetAt, resetAt, now+int64(interval)) {
+		atomic.AddUint64(&h.suppressed, 1)
+		return
+	}
+
+	b := bbpool.Get().(*bb)
+	b.B = append(b.B[:0], "log: failed to write "...)
+	b.B = append(b.B, e.Level.String()...)
+	b.B = append(b.B, " entry: "...)
+	b.B = append(b.B, err.Error()...)
+	if n := atomic.SwapUint64(&h.suppressed, 0); n != 0 {
+		b.B = append(b.B, " ("...)
+		b.B = strconv.AppendUint(b.B, n, 10)
+		b.B = append(b.B, " errors suppressed)"...)
+	}
+	b.B = append(b.B, '\n')
+	if h.Writer != nil {
+		_, _ = h.Writer.Write(b.B)
+	} else {
+		_, _ = os.Stderr.Write(b.B)
+	}
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+}
+
+// Errors returns the number of handled errors.
+func (h *RateLimitErrorHandler) Errors() uint64 {
+	return atomic.LoadUint64(&h.errors)
+}
+
+// Suppressed returns the number of errors not reported yet.
+func (h *RateLimitErrorHandler) Suppressed() uint64 {
+	return atomic.LoadUint64(&h.suppressed)
+}
+
+// handleError passes err of writing entry to the error handler of logger.
+func (e *Entry) handleError(err error) {
+	if e.errhandler != nil {
+		e.errhandler.HandleError(e, err)
+	} else {
+		DefaultErrorHandler.HandleError(e, err)
+	}
+}
+
+var _ ErrorHandler = (*RateLimitErrorHandler)(nil)
@@ -0,0 +1,119 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTestWriter = errors.New("test writer error")
+
+func TestErrorHandler(t *testing.T) {
+	var msgs []string
+	logger := Logger{
+		Level: InfoLevel,
+		ErrorHandler: ErrorHandlerFunc(func(e *Entry, err error) {
+			if err != errTestWriter {
+				t.Errorf("unexpected error: %+v", err)
+			}
+			msgs = append(msgs, string(e.buf))
+		}),
+		Writer: WriterFunc(func(e *Entry) (int, error) {
+			return 0, errTestWriter
+		}),
+	}
+
+	logger.Info().Str("foo", "bar").Msg("hello error handler")
+	if len(msgs) != 1 || !strings.Contains(msgs[0], `"foo":"bar","message":"hello error handler"`) {
+		t.Fatalf("error handler should receive the failed entry: %q", msgs)
+	}
+}
+
+func TestRateLimitErrorHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := &RateLimitErrorHandler{Interval: time.Hour, Writer: &buf}
+	logger := Logger{
+		Level:        InfoLevel,
+		ErrorHandler: h,
+		Writer: WriterFunc(func(e *Entry) (int, error) {
+			return 0, errTestWriter
+		}),
+	}
+
+	for i := 0; i < 3; i++ {
+		logger.Warn().Msg("hello")
+	}
+	if s := buf.String(); s != "log: failed to write warn entry: test writer error\n" {
+		t.Errorf("rate limit error handler should report the first error only: %q", s)
+	}
+	if h.Errors() != 3 || h.Suppressed() != 2 {
+		t.Errorf("rate limit error handler counters mismatch: errors=%d suppressed=%d", h.Errors(), h.Suppressed())
+	}
+
+	buf.Reset()
+	atomic.StoreInt64(&h.resetAt, 0)
+	logger.Error().Msg("hello")
+	if s := buf.String(); s != "log: failed to write error entry: test writer error (2 errors suppressed)\n" {
+		t.Errorf("rate limit error handler should report the suppressed errors: %q", s)
+	}
+}
+
+func TestFallbackWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &FallbackWriter{
+		Writer: WriterFunc(func(e *Entry) (int, error) {
+			return 0, errTestWriter
+		}),
+		Fallback: IOWriter{&buf},
+	}
+	var handled int
+	logger := Logger{
+		Level: InfoLevel,
+		ErrorHandler: ErrorHandlerFunc(func(e *Entry, err error) {
+			handled++
+		}),
+		Writer: w,
+	}
+
+	logger.Info().Msg("hello fallback")
+	if !strings.Contains(buf.String(), `"message":"hello fallback"`) || w.Failures() != 1 || handled != 0 {
+		t.Errorf("fallback writer should write to fallback: %q, failures=%d, handled=%d", buf.String(), w.Failures(), handled)
+	}
+
+	w.Fallback = WriterFunc(func(e *Entry) (int, error) {
+		return 0, errors.New("fallback error")
+	})
+	logger.Info().Msg("hello fallback")
+	if w.Failures() != 2 || handled != 1 {
+		t.Errorf("fallback writer should return the error of fallback: failures=%d, handled=%d", w.Failures(), handled)
+	}
+}
+
+func TestAsyncWriterErrorHandler(t *testing.T) {
+	var handled int64
+	w := &AsyncWriter{
+		ChannelSize: 10,
+		Writer: WriterFunc(func(e *Entry) (int, error) {
+			return 0, errTestWriter
+		}),
+	}
+	logger := Logger{
+		Level: InfoLevel,
+		ErrorHandler: ErrorHandlerFunc(func(e *Entry, err error) {
+			if err == errTestWriter {
+				atomic.AddInt64(&handled, 1)
+			}
+		}),
+		Writer: w,
+	}
+
+	logger.Info().Msg("hello async 1")
+	logger.Info().Msg("hello async 2")
+	w.Close()
+	if n := atomic.LoadInt64(&handled); n != 2 {
+		t.Errorf("async writer should pass errors to the error handler: %d", n)
+	}
+}
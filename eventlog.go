@@ -123,13 +123,30 @@ func (w *EventlogWriter) WriteEntry(e *Entry) (n int, err error) {
 		etype = EVENTLOG_AUDIT_FAILURE
 	default:
 		etype = EVENTLOG_INFORMATION_TYPE
+		if c := customLevel(e.Level); c != nil {
+			switch {
+			case c.Priority <= 3:
+				etype = EVENTLOG_ERROR_TYPE
+			case c.Priority == 4:
+				etype = EVENTLOG_WARNING_TYPE
+			}
+		}
 	}
 
 	var ecat uintptr = 0
 	var eid = w.ID
 	var ss = []*uint16{nil}
 
-	ss[0], err = syscall.UTF16PtrFromString(b2s(e.buf))
+	p := e.buf
+	if b := e.jsonbuf(); b != nil {
+		defer func() {
+			if cap(b.B) <= bbcap {
+				bbpool.Put(b)
+			}
+		}()
+		p = b.B
+	}
+	ss[0], err = syscall.UTF16PtrFromString(b2s(p))
 	if err != nil {
 		return
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -31,6 +32,42 @@ import (
 // number equal to MaxBackups (or all of them if MaxBackups is 0). Note that the
 // time encoded in the timestamp is the rotation time, which may differ from the
 // last time that file was written to.
+//
+// The log files modified earlier than MaxAge ago are deleted, and the oldest log
+// files are deleted until the total size of log files, including the compressed
+// backups and the current log file, does not exceed MaxTotalSize. If either is set,
+// a zero MaxBackups retains all log files within them. The first write after startup
+// also creates a new logfile, so the leftovers of previous processes are cleaned up.
+//
+// # Compressing Old Log Files
+//
+// If Compress is set, the log backups are compressed by Compressor in the background
+// after the cleanup, e.g. `/var/log/foo/server.2016-11-04T18-30-00.log.gz`. The compressed
+// backups keep their modification times and count towards MaxBackups.
+//
+// # Rotating Periodically
+//
+// If RotateInterval is set, the log file is also rotated by the first write after each
+// interval boundary, e.g. at the top of every hour or at every midnight. The boundaries
+// are aligned to the wall clock of local time if LocalTime is set, or UTC otherwise.
+//
+// # Renaming Log Files
+//
+// If Rename is set, the logs are always written to Filename as a regular file, and
+// the log file is renamed to a timestamped backup on rotation, or to a numbered backup
+// such as `/var/log/foo/server.log.1` if NumberedBackups is set, where the existing
+// numbered backups are shifted in the background. Filename is checked every second,
+// it is reopened if moved or removed by external tools such as logrotate, and a
+// truncation by the copytruncate of logrotate is detected by the size shrink.
+//
+// # Buffering and Syncing
+//
+// If BufferSize is set, the writes are buffered in process, and the buffer is written
+// to the log file when full, every FlushInterval, by Flush, Close and rotation. The
+// buffered FileWriter registers itself by RegisterFlusher, so the buffer is flushed
+// before a fatal entry exits. The log file is committed to stable storage by fsync
+// every SyncInterval, after the entries at or above SyncLevel, by Sync, Close and
+// rotation if either is set, otherwise it is left to the operating system.
 type FileWriter struct {
 	// Filename is the file to write logs to.  Backup log files will be retained
 	// in the same directory.
@@ -39,14 +76,31 @@ type FileWriter struct {
 	// MaxSize is the maximum size in bytes of the log file before it gets rotated.
 	MaxSize int64
 
+	// RotateInterval is the interval of rotating the log file periodically, e.g. time.Hour
+	// for hourly or 24*time.Hour for daily. It is disabled if zero.
+	RotateInterval time.Duration
+
 	// MaxBackups is the maximum number of old log files to retain.  The default
 	// is to retain all old log files
 	MaxBackups int
 
+	// MaxAge is the maximum duration to retain old log files based on their
+	// modification times.  The default is not to remove old log files based on age.
+	MaxAge time.Duration
+
+	// MaxTotalSize is the maximum total size in bytes of log files to retain.  The
+	// default is not to remove old log files based on size.
+	MaxTotalSize int64
+
 	// make aligncheck happy
-	mu   sync.Mutex
-	size int64
-	file *os.File
+	mu       sync.Mutex
+	size     int64
+	rotateat int64
+	checkat  int64
+	file     *os.File
+	buf      []byte
+	done     chan struct{}
+	dirty    bool
 
 	// FileMode represents the file's mode and permission bits.  The default
 	// mode is 0644
@@ -73,8 +127,42 @@ type FileWriter struct {
 	Header func(fileinfo os.FileInfo) []byte
 
 	// Cleaner specifies an optional cleanup function of log backups after rotation,
-	// if not set, the default behavior is to delete more than MaxBackups log files.
+	// if not set, the default behavior is to delete log files exceeding MaxBackups,
+	// MaxAge or MaxTotalSize.
 	Cleaner func(filename string, maxBackups int, matches []os.FileInfo)
+
+	// Compress determines if the log backups are compressed in the background after rotation.
+	Compress bool
+
+	// Compressor specifies the compressor of log backups, it uses GzipCompressor if empty.
+	Compressor Compressor
+
+	// Rename determines if the logs are written to Filename, and it is renamed to a backup
+	// on rotation, instead of pointing the symlink of Filename to the timestamped log files.
+	Rename bool
+
+	// NumberedBackups determines if the backups are numbered from the newest, e.g.
+	// `server.log.1`, instead of timestamped. It takes effect only if Rename is set.
+	NumberedBackups bool
+
+	// BufferSize is the size in bytes of the in-process buffer of writes.  It is
+	// disabled if zero.
+	BufferSize int
+
+	// FlushInterval is the interval of flushing the buffer periodically.  It is
+	// disabled if zero, then the buffer is flushed when full, by Flush, Close and rotation.
+	FlushInterval time.Duration
+
+	// SyncInterval is the interval of committing the log file to stable storage by
+	// fsync.  It is disabled if zero.
+	SyncInterval time.Duration
+
+	// SyncLevel determines if the log file is committed to stable storage by fsync
+	// after writing the entries at or above the level.  It is disabled if empty.
+	SyncLevel Level
+
+	// cleanmu serializes the cleanup and compression of log backups after rotations.
+	cleanmu sync.Mutex
 }
 
 // WriteEntry implements Writer.  If a write would cause the log file to be larger
@@ -83,6 +171,9 @@ type FileWriter struct {
 func (w *FileWriter) WriteEntry(e *Entry) (n int, err error) {
 	w.mu.Lock()
 	n, err = w.write(e.buf)
+	if err == nil && w.synclevel(e.Level) && w.file != nil {
+		err = w.flush(true)
+	}
 	w.mu.Unlock()
 	return
 }
@@ -98,11 +189,38 @@ func (w *FileWriter) Write(p []byte) (n int, err error) {
 }
 
 func (w *FileWriter) write(p []byte) (n int, err error) {
+	if w.file == nil && w.Filename == "" {
+		n, err = os.Stderr.Write(p)
+		return
+	}
+
+	err = w.prepare()
+	if err != nil {
+		return
+	}
+
+	if w.BufferSize > 0 {
+		n, err = w.buffer(p)
+	} else {
+		n, err = w.file.Write(p)
+		w.dirty = true
+	}
+	if err != nil {
+		return
+	}
+
+	w.size += int64(n)
+	if w.MaxSize > 0 && w.size > w.MaxSize && w.Filename != "" {
+		err = w.rotate()
+	}
+
+	return
+}
+
+// prepare creates the log file if not opened, or checks and rotates it by Rename
+// and RotateInterval before writing.
+func (w *FileWriter) prepare() (err error) {
 	if w.file == nil {
-		if w.Filename == "" {
-			n, err = os.Stderr.Write(p)
-			return
-		}
 		if w.EnsureFolder {
 			err = os.MkdirAll(filepath.Dir(w.Filename), 0755)
 			if err != nil {
@@ -110,36 +228,204 @@ func (w *FileWriter) write(p []byte) (n int, err error) {
 			}
 		}
 		err = w.create()
+		if err == nil {
+			w.start()
+		}
+		return
+	}
+
+	if w.Rename || w.RotateInterval > 0 {
+		now := timeNow().UnixNano()
+		if w.Rename && now >= w.checkat {
+			err = w.check(now)
+			if err != nil {
+				return
+			}
+		}
+		if w.RotateInterval > 0 && now >= w.rotateat {
+			err = w.rotate()
+		}
+	}
+
+	return
+}
+
+// buffer appends p to the buffer, the buffer is written to the log file before it overflows.
+func (w *FileWriter) buffer(p []byte) (n int, err error) {
+	if len(w.buf)+len(p) > w.BufferSize {
+		err = w.flush(false)
 		if err != nil {
 			return
 		}
 	}
+	if len(p) >= w.BufferSize {
+		w.dirty = true
+		return w.file.Write(p)
+	}
+	if w.buf == nil {
+		w.buf = make([]byte, 0, w.BufferSize)
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
 
-	n, err = w.file.Write(p)
-	if err != nil {
-		return
+// flush writes the buffer to the log file, and commits the log file to stable storage if sync is true.
+func (w *FileWriter) flush(sync bool) (err error) {
+	if len(w.buf) != 0 {
+		w.dirty = true
+		_, err = w.file.Write(w.buf)
+		w.buf = w.buf[:0]
 	}
+	if err == nil && sync && w.dirty {
+		err = w.file.Sync()
+		w.dirty = false
+	}
+	return
+}
 
-	w.size += int64(n)
-	if w.MaxSize > 0 && w.size > w.MaxSize && w.Filename != "" {
-		err = w.rotate()
+// synclevel reports whether the entries of level are committed to stable storage.
+func (w *FileWriter) synclevel(level Level) bool {
+	return w.SyncLevel != 0 && level.rank() >= w.SyncLevel.rank() && level != noLevel
+}
+
+// syncing reports whether the log file is committed to stable storage on rotation and close.
+func (w *FileWriter) syncing() bool {
+	return w.SyncInterval > 0 || w.SyncLevel != 0
+}
+
+// closefile flushes and closes the log file.
+func (w *FileWriter) closefile() (err error) {
+	err = w.flush(w.syncing())
+	if err1 := w.file.Close(); err == nil {
+		err = err1
+	}
+	w.file = nil
+	w.size = 0
+	w.dirty = false
+	return
+}
+
+// start registers the buffered writer to be flushed, and starts flushing and syncing periodically.
+func (w *FileWriter) start() {
+	if w.BufferSize > 0 {
+		RegisterFlusher(w)
+	}
+	if w.done == nil && ((w.BufferSize > 0 && w.FlushInterval > 0) || w.SyncInterval > 0) {
+		w.done = make(chan struct{})
+		go w.background(w.done)
+	}
+}
+
+// background flushes the buffer every FlushInterval and syncs the log file every SyncInterval until done is closed.
+func (w *FileWriter) background(done <-chan struct{}) {
+	var flushc, syncc <-chan time.Time
+	if w.BufferSize > 0 && w.FlushInterval > 0 {
+		ticker := time.NewTicker(w.FlushInterval)
+		defer ticker.Stop()
+		flushc = ticker.C
+	}
+	if w.SyncInterval > 0 {
+		ticker := time.NewTicker(w.SyncInterval)
+		defer ticker.Stop()
+		syncc = ticker.C
+	}
+
+	for {
+		var sync bool
+		select {
+		case <-done:
+			return
+		case <-flushc:
+		case <-syncc:
+			sync = true
+		}
+		w.mu.Lock()
+		if w.file != nil {
+			_ = w.flush(sync)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// Flush implements Flusher, and writes the buffer to the log file.
+func (w *FileWriter) Flush() (err error) {
+	w.mu.Lock()
+	if w.file != nil {
+		err = w.flush(false)
 	}
+	w.mu.Unlock()
+	return
+}
 
+// Sync writes the buffer to the log file, and commits the log file to stable storage.
+func (w *FileWriter) Sync() (err error) {
+	w.mu.Lock()
+	if w.file != nil {
+		err = w.flush(true)
+	}
+	w.mu.Unlock()
 	return
 }
 
-// Close implements io.Closer, and closes the current logfile.
+// Close implements io.Closer, and flushes and closes the current logfile.
 func (w *FileWriter) Close() (err error) {
 	w.mu.Lock()
 	if w.file != nil {
-		err = w.file.Close()
-		w.file = nil
-		w.size = 0
+		err = w.closefile()
+	}
+	if w.done != nil {
+		close(w.done)
+		w.done = nil
+	}
+	w.mu.Unlock()
+	if w.BufferSize > 0 {
+		UnregisterFlusher(w)
+	}
+	return
+}
+
+// Reopen closes and reopens the log file, it is useful for the external log rotation
+// tools, e.g. the postrotate script of logrotate sends a SIGHUP after renaming the log
+// file.  Without Rename, a new log file is created by the next write.
+func (w *FileWriter) Reopen() (err error) {
+	w.mu.Lock()
+	if w.file != nil {
+		err = w.closefile()
+	}
+	if err == nil && w.Rename && w.Filename != "" {
+		if err = w.open(); err == nil {
+			w.start()
+		}
 	}
 	w.mu.Unlock()
 	return
 }
 
+// ReopenOnSignal reopens the log file on receiving any of sigs, e.g. syscall.SIGHUP.
+// It returns a function to stop the reopening.
+func (w *FileWriter) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		for {
+			select {
+			case <-c:
+				_ = w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
 // Rotate causes Logger to close the existing log file and immediately create a
 // new one.  This is a helper function for applications that want to initiate
 // rotations outside of the normal rotation rules, such as in response to
@@ -153,12 +439,24 @@ func (w *FileWriter) Rotate() (err error) {
 }
 
 func (w *FileWriter) rotate() (err error) {
+	if w.Rename {
+		return w.rename()
+	}
+
+	if w.file != nil {
+		err = w.flush(w.syncing())
+		if err != nil {
+			return err
+		}
+	}
+
 	var file *os.File
-	file, err = os.OpenFile(w.fileargs(timeNow()))
+	now := timeNow()
+	file, err = os.OpenFile(w.fileargs(now))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) && w.EnsureFolder {
 			if err = os.MkdirAll(filepath.Dir(w.Filename), 0755); err == nil {
-				file, err = os.OpenFile(w.fileargs(timeNow()))
+				file, err = os.OpenFile(w.fileargs(now))
 			}
 		}
 		if err != nil {
@@ -170,6 +468,13 @@ func (w *FileWriter) rotate() (err error) {
 	}
 	w.file = file
 	w.size = 0
+	w.dirty = false
+	if w.RotateInterval > 0 {
+		if !w.LocalTime {
+			now = now.UTC()
+		}
+		w.rotateat = w.nextRotation(now).UnixNano()
+	}
 
 	if w.Header != nil {
 		st, err := file.Stat()
@@ -191,54 +496,360 @@ func (w *FileWriter) rotate() (err error) {
 			_ = os.Symlink(filepath.Base(newname), w.Filename)
 		}
 
-		uid, _ := strconv.Atoi(os.Getenv("SUDO_UID"))
-		gid, _ := strconv.Atoi(os.Getenv("SUDO_GID"))
-		if uid != 0 && gid != 0 && os.Geteuid() == 0 {
+		if uid, gid, ok := sudoer(); ok {
 			_ = os.Lchown(w.Filename, uid, gid)
 			_ = os.Chown(newname, uid, gid)
 		}
 
-		dir := filepath.Dir(w.Filename)
-		dirfile, err := os.Open(dir)
+		w.cleanup(newname)
+	}(w.file.Name())
+
+	return
+}
+
+// rename renames Filename to a backup and reopens it.  The numbered backups are
+// renamed to a pending name, which is numbered by cleanup later.
+func (w *FileWriter) rename() (err error) {
+	if w.file != nil {
+		// an opened file cannot be renamed on windows
+		err = w.closefile()
 		if err != nil {
-			return
+			return err
 		}
-		infos, err := dirfile.Readdir(-1)
-		dirfile.Close()
-		if err != nil {
-			return
+	}
+
+	now := timeNow()
+	var backup string
+	if w.numbered() {
+		backup = w.Filename + pendingInfix + strconv.FormatInt(now.UnixNano(), 10)
+	} else {
+		backup, _, _ = w.fileargs(now)
+		_, suffix := w.fileparts()
+		name := backup[:len(backup)-len(suffix)]
+		for i := 1; ; i++ {
+			if _, err := os.Lstat(backup); os.IsNotExist(err) {
+				break
+			}
+			backup = name + "." + strconv.Itoa(i) + suffix
 		}
+	}
 
-		base, ext := filepath.Base(w.Filename), filepath.Ext(w.Filename)
-		prefix, extgz := base[:len(base)-len(ext)]+".", ext+".gz"
-		exclude := prefix + "error" + ext
+	err = os.Rename(w.Filename, backup)
+	if err != nil && !os.IsNotExist(err) {
+		// keeps writing to Filename
+		if err1 := w.open(); err1 != nil {
+			return err1
+		}
+		return err
+	}
 
-		matches := make([]os.FileInfo, 0)
-		for _, info := range infos {
-			name := info.Name()
-			if name != base && name != exclude &&
-				strings.HasPrefix(name, prefix) &&
-				(strings.HasSuffix(name, ext) || strings.HasSuffix(name, extgz)) {
-				matches = append(matches, info)
-			}
+	return w.open()
+}
+
+// open opens Filename for writing in Rename mode, and cleans up the log backups in the background.
+func (w *FileWriter) open() (err error) {
+	if fi, err := os.Lstat(w.Filename); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		// the symlink left by the non-Rename mode
+		os.Remove(w.Filename)
+	}
+
+	now := timeNow()
+	_, flag, perm := w.fileargs(now)
+	w.file, err = os.OpenFile(w.Filename, flag, perm)
+	if err != nil {
+		return err
+	}
+	w.size = 0
+	w.checkat = now.UnixNano() + int64(time.Second)
+	if w.RotateInterval > 0 {
+		if !w.LocalTime {
+			now = now.UTC()
 		}
-		sort.Slice(matches, func(i, j int) bool {
-			return matches[i].ModTime().Unix() < matches[j].ModTime().Unix()
-		})
+		w.rotateat = w.nextRotation(now).UnixNano()
+	}
 
-		if w.Cleaner != nil {
-			w.Cleaner(w.Filename, w.MaxBackups, matches)
-		} else {
-			for i := 0; i < len(matches)-w.MaxBackups-1; i++ {
-				os.Remove(filepath.Join(dir, matches[i].Name()))
+	st, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	w.size = st.Size()
+	if w.size == 0 && w.Header != nil {
+		if b := w.Header(st); b != nil {
+			n, err := w.file.Write(b)
+			w.size += int64(n)
+			if err != nil {
+				return err
 			}
 		}
-	}(w.file.Name())
+	}
+
+	go func() {
+		if uid, gid, ok := sudoer(); ok {
+			_ = os.Chown(w.Filename, uid, gid)
+		}
+		w.cleanup(w.Filename)
+	}()
 
 	return
 }
 
+// check reopens Filename if it is moved or removed, and updates the size if it is truncated
+// by external tools.
+func (w *FileWriter) check(now int64) error {
+	w.checkat = now + int64(time.Second)
+	st, err := w.file.Stat()
+	if err != nil {
+		return nil
+	}
+	if fi, err := os.Stat(w.Filename); err != nil || !os.SameFile(st, fi) {
+		// the buffer is written to the moved log file
+		_ = w.closefile()
+		return w.open()
+	}
+	// the log file may be truncated by copytruncate
+	w.size = st.Size() + int64(len(w.buf))
+	return nil
+}
+
+// numbered reports whether the backups are numbered.
+func (w *FileWriter) numbered() bool {
+	return w.Rename && w.NumberedBackups
+}
+
+// pendingInfix is the infix of the backup names waiting to be numbered.
+const pendingInfix = ".pending."
+
+// backupNumber returns the number and the compression extension of a numbered backup name.
+func (w *FileWriter) backupNumber(name string) (n int, ext string, ok bool) {
+	base := filepath.Base(w.Filename) + "."
+	if !strings.HasPrefix(name, base) {
+		return
+	}
+	s := name[len(base):]
+	i := 0
+	for i < len(s) && i < 9 && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	if ext = s[i:]; i == 0 || (ext != "" && ext != ".gz" && ext != w.compressor().Ext()) {
+		return 0, "", false
+	}
+	n, _ = strconv.Atoi(s[:i])
+	return n, ext, n > 0
+}
+
+// shift numbers the pending backups from 1 by the rename order, and shifts the numbers
+// of the existing numbered backups.
+func (w *FileWriter) shift() {
+	dir := filepath.Dir(w.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type numbered struct {
+		n   int
+		ext string
+	}
+	pending := filepath.Base(w.Filename) + pendingInfix
+	var names []string
+	var backups []numbered
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, pending) {
+			names = append(names, name)
+		} else if n, ext, ok := w.backupNumber(name); ok {
+			backups = append(backups, numbered{n, ext})
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	// the pending names end with the fixed width unix nanoseconds
+	sort.Strings(names)
+	sort.Slice(backups, func(i, j int) bool { return backups[i].n > backups[j].n })
+
+	for _, b := range backups {
+		os.Rename(w.Filename+"."+strconv.Itoa(b.n)+b.ext, w.Filename+"."+strconv.Itoa(b.n+len(names))+b.ext)
+	}
+	for i, name := range names {
+		os.Rename(filepath.Join(dir, name), w.Filename+"."+strconv.Itoa(len(names)-i))
+	}
+}
+
+// cleanup removes the log backups exceeding MaxBackups, MaxAge, MaxTotalSize or by Cleaner,
+// and compresses the rest except current if Compress is set.
+func (w *FileWriter) cleanup(current string) {
+	w.cleanmu.Lock()
+	defer w.cleanmu.Unlock()
+
+	if w.numbered() {
+		w.shift()
+	}
+
+	dir := filepath.Dir(w.Filename)
+	matches, err := w.backups()
+	if err != nil {
+		return
+	}
+
+	// the log file may be rotated again since current
+	active := current
+	w.mu.Lock()
+	if w.file != nil {
+		active = w.file.Name()
+	}
+	w.mu.Unlock()
+
+	if w.Cleaner != nil {
+		w.Cleaner(w.Filename, w.MaxBackups, matches)
+	} else {
+		w.retain(matches, filepath.Base(current), filepath.Base(active))
+	}
+
+	if !w.Compress {
+		return
+	}
+	if matches, err = w.backups(); err != nil {
+		return
+	}
+	compressor := w.compressor()
+	_, suffix := w.fileparts()
+	for _, info := range matches {
+		name := info.Name()
+		switch {
+		case name == filepath.Base(current), name == filepath.Base(active),
+			!w.numbered() && !strings.HasSuffix(name, suffix),
+			strings.HasSuffix(name, compressor.Ext()):
+			continue
+		}
+		_ = compressFile(compressor, filepath.Join(dir, name), info)
+	}
+}
+
+// retain removes the log files of matches exceeding MaxBackups, MaxAge or MaxTotalSize
+// from the oldest, except the current and active ones.
+func (w *FileWriter) retain(matches []os.FileInfo, current, active string) {
+	dir := filepath.Dir(w.Filename)
+	maxBackups := len(matches)
+	if w.MaxBackups > 0 || (w.MaxAge == 0 && w.MaxTotalSize == 0) {
+		maxBackups = w.MaxBackups
+	}
+	var deadline time.Time
+	if w.MaxAge > 0 {
+		deadline = timeNow().Add(-w.MaxAge)
+	}
+
+	var total int64
+	count := len(matches)
+	if w.Rename {
+		// the current log file is not one of matches
+		if st, err := os.Stat(w.Filename); err == nil {
+			total = st.Size()
+		}
+		count++
+	}
+	for i := len(matches) - 1; i >= 0; i-- {
+		info := matches[i]
+		total += info.Size()
+		if name := info.Name(); name == current || name == active {
+			continue
+		}
+		if i < count-maxBackups-1 ||
+			(w.MaxAge > 0 && info.ModTime().Before(deadline)) ||
+			(w.MaxTotalSize > 0 && total > w.MaxTotalSize) {
+			if os.Remove(filepath.Join(dir, info.Name())) == nil {
+				total -= info.Size()
+			}
+		}
+	}
+}
+
+// backups returns the log backups sorted by modification time, or by number from the oldest
+// if the backups are numbered.  It includes the current log file unless Rename is set.
+// It removes the temporary files left by the interrupted compressions of this writer.
+func (w *FileWriter) backups() ([]os.FileInfo, error) {
+	dir := filepath.Dir(w.Filename)
+	dirfile, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := dirfile.Readdir(-1)
+	dirfile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	base, ext := filepath.Base(w.Filename), filepath.Ext(w.Filename)
+	prefix, extgz, extc := base[:len(base)-len(ext)]+".", ext+".gz", ext+w.compressor().Ext()
+	exclude := prefix + "error" + ext
+	tmpsuffix := ""
+	if w.Compress {
+		_, suffix := w.fileparts()
+		tmpsuffix = suffix + w.compressor().Ext() + ".tmp"
+	}
+
+	numbered, tmpnumbered := w.numbered(), w.compressor().Ext()+".tmp"
+	matches := make([]os.FileInfo, 0)
+	for _, info := range infos {
+		name := info.Name()
+		if numbered {
+			if _, _, ok := w.backupNumber(name); ok {
+				matches = append(matches, info)
+			} else if w.Compress && strings.HasPrefix(name, base+".") && strings.HasSuffix(name, tmpnumbered) {
+				os.Remove(filepath.Join(dir, name))
+			}
+			continue
+		}
+		if name != base && name != exclude &&
+			strings.HasPrefix(name, prefix) &&
+			(strings.HasSuffix(name, ext) || strings.HasSuffix(name, extgz) || strings.HasSuffix(name, extc)) {
+			matches = append(matches, info)
+		} else if tmpsuffix != "" && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, tmpsuffix) {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if numbered {
+			n1, _, _ := w.backupNumber(matches[i].Name())
+			n2, _, _ := w.backupNumber(matches[j].Name())
+			return n1 > n2
+		}
+		if t1, t2 := matches[i].ModTime(), matches[j].ModTime(); !t1.Equal(t2) {
+			return t1.Before(t2)
+		}
+		return matches[i].Name() < matches[j].Name()
+	})
+
+	return matches, nil
+}
+
+// nextRotation returns the first interval boundary after now, aligned to the wall clock of now's location.
+func (w *FileWriter) nextRotation(now time.Time) time.Time {
+	_, offset := now.Zone()
+	zone := time.Duration(offset) * time.Second
+	next := now.Add(zone).Truncate(w.RotateInterval).Add(w.RotateInterval - zone)
+	// the offset may change across daylight saving time transitions
+	if _, offset2 := next.Zone(); offset2 != offset {
+		zone2 := time.Duration(offset2) * time.Second
+		if t := next.Add(zone2); !t.Equal(t.Truncate(w.RotateInterval)) {
+			next = next.Add(zone - zone2)
+		}
+	}
+	return next
+}
+
+func (w *FileWriter) compressor() Compressor {
+	if w.Compressor != nil {
+		return w.Compressor
+	}
+	return GzipCompressor{}
+}
+
 func (w *FileWriter) create() (err error) {
+	if w.Rename {
+		return w.open()
+	}
+
 	w.file, err = os.OpenFile(w.fileargs(timeNow()))
 	if err != nil {
 		return err
@@ -279,8 +890,7 @@ func (w *FileWriter) fileargs(now time.Time) (filename string, flag int, perm os
 	}
 
 	// filename
-	ext := filepath.Ext(w.Filename)
-	prefix := w.Filename[0 : len(w.Filename)-len(ext)]
+	prefix, suffix := w.fileparts()
 	switch w.TimeFormat {
 	case "":
 		filename = prefix + now.Format(".2006-01-02T15-04-05")
@@ -291,19 +901,7 @@ func (w *FileWriter) fileargs(now time.Time) (filename string, flag int, perm os
 	default:
 		filename = prefix + "." + now.Format(w.TimeFormat)
 	}
-	if w.HostName {
-		if w.ProcessID {
-			filename += "." + hostname + "-" + strconv.Itoa(pid) + ext
-		} else {
-			filename += "." + hostname + ext
-		}
-	} else {
-		if w.ProcessID {
-			filename += "." + strconv.Itoa(pid) + ext
-		} else {
-			filename += ext
-		}
-	}
+	filename += suffix
 
 	// flag
 	flag = os.O_APPEND | os.O_CREATE | os.O_WRONLY
@@ -317,6 +915,26 @@ func (w *FileWriter) fileargs(now time.Time) (filename string, flag int, perm os
 	return
 }
 
+// fileparts returns the prefix and suffix of log file names around the timestamp.
+func (w *FileWriter) fileparts() (prefix, suffix string) {
+	ext := filepath.Ext(w.Filename)
+	prefix = w.Filename[0 : len(w.Filename)-len(ext)]
+	if w.HostName {
+		if w.ProcessID {
+			suffix = "." + hostname + "-" + strconv.Itoa(pid) + ext
+		} else {
+			suffix = "." + hostname + ext
+		}
+	} else {
+		if w.ProcessID {
+			suffix = "." + strconv.Itoa(pid) + ext
+		} else {
+			suffix = ext
+		}
+	}
+	return
+}
+
 var hostname, machine = func() (string, [16]byte) {
 	// host
 	host, err := os.Hostname()
@@ -346,5 +964,12 @@ var hostname, machine = func() (string, [16]byte) {
 
 var pid = os.Getpid()
 
+// sudoer returns the uid and gid of the user who runs the process as root by sudo.
+func sudoer() (uid, gid int, ok bool) {
+	uid, _ = strconv.Atoi(os.Getenv("SUDO_UID"))
+	gid, _ = strconv.Atoi(os.Getenv("SUDO_GID"))
+	return uid, gid, uid != 0 && gid != 0 && os.Geteuid() == 0
+}
+
 var _ Writer = (*FileWriter)(nil)
 var _ io.Writer = (*FileWriter)(nil)
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"runtime"
+)
+
+// Compressor defines an interface to compress the log backups of FileWriter.
+type Compressor interface {
+	// Ext returns the file extension of compressed backups, e.g. ".gz".
+	Ext() string
+
+	// Compress writes the compressed data of src to dst.
+	Compress(dst io.Writer, src io.Reader) error
+}
+
+// GzipCompressor is a Compressor of gzip format.
+type GzipCompressor struct {
+	// Level specifies the gzip compression level, it uses gzip.DefaultCompression if empty.
+	Level int
+}
+
+// Ext implements Compressor.
+func (c GzipCompressor) Ext() string {
+	return ".gz"
+}
+
+// Compress implements Compressor.
+func (c GzipCompressor) Compress(dst io.Writer, src io.Reader) error {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	zw, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// compressing limits the concurrent compressions of log backups of all FileWriters.
+var compressing = make(chan struct{}, (runtime.NumCPU()+1)/2)
+
+// compressFile compresses the log backup of name to a temporary file, then renames it
+// to name with the extension of compressor and removes name. The compressed file keeps
+// the modification time of name, so that the order of backups is preserved.
+func compressFile(c Compressor, name string, info os.FileInfo) error {
+	compressing <- struct{}{}
+	defer func() { <-compressing }()
+
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	dst := name + c.Ext()
+	tmp := dst + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err == nil {
+		err = c.Compress(file, src)
+		if err == nil {
+			err = file.Sync()
+		}
+		if err1 := file.Close(); err == nil {
+			err = err1
+		}
+	}
+	src.Close()
+
+	if err == nil {
+		err = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
+
+var _ Compressor = GzipCompressor{}
@@ -3,8 +3,6 @@
 package log
 
 import (
-	"os"
-	"path/filepath"
 	"syscall"
 	"unsafe"
 )
@@ -13,25 +11,26 @@ func (w *FileWriter) WriteV(iovs []syscall.Iovec) (n uintptr, err error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.file == nil {
-		if w.Filename == "" {
-			n, err = writev(syscall.Stderr, iovs)
-			if n == ^uintptr(0) { // -1 means aborted
-				n = 0
-			}
-			return
-		}
-		if w.EnsureFolder {
-			err = os.MkdirAll(filepath.Dir(w.Filename), 0755)
-			if err != nil {
-				return
-			}
+	if w.file == nil && w.Filename == "" {
+		n, err = writev(syscall.Stderr, iovs)
+		if n == ^uintptr(0) { // -1 means aborted
+			n = 0
 		}
-		err = w.create()
+		return
+	}
+
+	err = w.prepare()
+	if err != nil {
+		return
+	}
+
+	if len(w.buf) != 0 {
+		err = w.flush(false)
 		if err != nil {
 			return
 		}
 	}
+	w.dirty = true
 
 	n, err = writev(int(w.file.Fd()), iovs)
 	if n == ^uintptr(0) { // -1 means aborted
@@ -1,9 +1,16 @@
 package log
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -511,3 +518,647 @@ func TestFileWriter_MaxSizeRotation_SingleVsMultiInstance(t *testing.T) {
 		})
 	}
 }
+
+// waitFiles waits for the background cleanup and returns the files of pattern.
+func waitFiles(t *testing.T, pattern string, ok func([]string) bool) []string {
+	t.Helper()
+	var matches []string
+	for i := 0; i < 200; i++ {
+		matches, _ = filepath.Glob(pattern)
+		if ok(matches) {
+			return matches
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("files of %s are unexpected: %v", pattern, matches)
+	return nil
+}
+
+func TestFileWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:   filepath.Join(dir, "file-compress.log"),
+		TimeFormat: TimeFormatUnixMs,
+		MaxBackups: 2,
+		Compress:   true,
+	}
+	defer w.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := wlprintf(w, InfoLevel, "hello file writer %d!\n", i); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+		if i < 3 {
+			if err := w.Rotate(); err != nil {
+				t.Fatalf("file writer rotate error: %+v", err)
+			}
+		}
+	}
+
+	// the current file and 2 compressed backups, the oldest backup is removed
+	waitFiles(t, filepath.Join(dir, "file-compress.*.log.gz"), func(m []string) bool { return len(m) == 2 })
+	waitFiles(t, filepath.Join(dir, "file-compress.*.log"), func(m []string) bool { return len(m) == 1 })
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmps) != 0 {
+		t.Fatalf("temporary files must be renamed: %v", tmps)
+	}
+
+	backups, err := w.backups()
+	if err != nil || len(backups) != 3 {
+		t.Fatalf("backups must include compressed files: %v %v", backups, err)
+	}
+	for i, info := range backups[:2] {
+		file, err := os.Open(filepath.Join(dir, info.Name()))
+		if err != nil {
+			t.Fatalf("open compressed file error: %+v", err)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("gzip reader error: %+v", err)
+		}
+		data, err := io.ReadAll(zr)
+		file.Close()
+		if want := fmt.Sprintf("hello file writer %d!\n", i+1); err != nil || string(data) != want {
+			t.Fatalf("compressed file %s content want %q, got %q %v", info.Name(), want, data, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, backups[2].Name())); string(data) != "hello file writer 3!\n" {
+		t.Fatalf("current file must not be compressed: %s %q", backups[2].Name(), data)
+	}
+}
+
+type upperCompressor struct{}
+
+func (upperCompressor) Ext() string { return ".up" }
+
+func (upperCompressor) Compress(dst io.Writer, src io.Reader) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(bytes.ToUpper(data))
+	return err
+}
+
+func TestFileWriterCompressor(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:   filepath.Join(dir, "file-compressor.log"),
+		TimeFormat: TimeFormatUnixMs,
+		MaxBackups: 10,
+		ProcessID:  true,
+		Compress:   true,
+		Compressor: upperCompressor{},
+	}
+	defer w.Close()
+
+	// the backup of another process and the leftover of an interrupted compression
+	other := filepath.Join(dir, "file-compressor.1000.1.log")
+	leftover := filepath.Join(dir, fmt.Sprintf("file-compressor.1000.%d.log.up.tmp", pid))
+	for _, name := range []string{other, leftover} {
+		if err := os.WriteFile(name, []byte("other\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := wlprintf(w, InfoLevel, "hello compressor\n"); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("file writer rotate error: %+v", err)
+	}
+
+	matches := waitFiles(t, filepath.Join(dir, "*.up"), func(m []string) bool { return len(m) == 1 })
+	if data, _ := os.ReadFile(matches[0]); string(data) != "HELLO COMPRESSOR\n" {
+		t.Fatalf("wrong content of compressed file: %q", data)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("backups of other processes must not be compressed: %v", err)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatalf("leftover of interrupted compression must be removed: %v", err)
+	}
+}
+
+func TestGzipCompressor(t *testing.T) {
+	var b bytes.Buffer
+	text := strings.Repeat("hello gzip compressor\n", 100)
+	if err := (GzipCompressor{Level: gzip.BestSpeed}).Compress(&b, strings.NewReader(text)); err != nil {
+		t.Fatalf("gzip compress error: %+v", err)
+	}
+	zr, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatalf("gzip reader error: %+v", err)
+	}
+	if data, err := io.ReadAll(zr); err != nil || string(data) != text {
+		t.Fatalf("gzip decompress mismatch: %v", err)
+	}
+
+	if err := (GzipCompressor{Level: 100}).Compress(&b, strings.NewReader(text)); err == nil {
+		t.Fatalf("invalid gzip level must return error")
+	}
+}
+
+func TestFileWriterRotateInterval(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:       filepath.Join(dir, "file-interval.log"),
+		TimeFormat:     TimeFormatUnixMs,
+		RotateInterval: 300 * time.Millisecond,
+	}
+	defer w.Close()
+
+	// starts right after a boundary
+	time.Sleep(time.Until(timeNow().Truncate(w.RotateInterval).Add(w.RotateInterval + 10*time.Millisecond)))
+	for _, s := range []string{"a\n", "b\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+	}
+	first := w.file.Name()
+	time.Sleep(time.Until(time.Unix(0, w.rotateat).Add(10 * time.Millisecond)))
+	if _, err := w.Write([]byte("c\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+
+	if w.file.Name() == first {
+		t.Fatalf("file writer must rotate after the interval boundary: %s", first)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "a\nb\n" {
+		t.Fatalf("file writer must not rotate within the interval: %q", data)
+	}
+	if data, _ := os.ReadFile(w.file.Name()); string(data) != "c\n" {
+		t.Fatalf("file writer must write to the rotated file: %q", data)
+	}
+}
+
+func TestFileWriterNextRotation(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	ist := time.FixedZone("IST", 5*3600+1800)
+	cases := []struct {
+		Interval time.Duration
+		Now      time.Time
+		Next     time.Time
+	}{
+		{time.Hour, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), time.Date(2020, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{time.Hour, time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{time.Hour, time.Date(2020, 1, 2, 3, 4, 5, 0, ist), time.Date(2020, 1, 2, 4, 0, 0, 0, ist)},
+		{24 * time.Hour, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{24 * time.Hour, time.Date(2020, 1, 2, 23, 4, 5, 0, cst), time.Date(2020, 1, 3, 0, 0, 0, 0, cst)},
+		{15 * time.Minute, time.Date(2020, 1, 2, 3, 4, 5, 0, cst), time.Date(2020, 1, 2, 3, 15, 0, 0, cst)},
+		{6 * time.Hour, time.Date(2020, 1, 2, 13, 4, 5, 0, cst), time.Date(2020, 1, 2, 18, 0, 0, 0, cst)},
+	}
+	if ny, err := time.LoadLocation("America/New_York"); err == nil {
+		cases = append(cases, []struct {
+			Interval time.Duration
+			Now      time.Time
+			Next     time.Time
+		}{
+			// daylight saving time ends at 2020-11-01 02:00 EDT
+			{24 * time.Hour, time.Date(2020, 11, 1, 0, 30, 0, 0, ny), time.Date(2020, 11, 2, 0, 0, 0, 0, ny)},
+			{time.Hour, time.Date(2020, 11, 1, 1, 30, 0, 0, ny), time.Date(2020, 11, 1, 6, 0, 0, 0, time.UTC)},
+			// daylight saving time starts at 2020-03-08 02:00 EST
+			{24 * time.Hour, time.Date(2020, 3, 8, 0, 30, 0, 0, ny), time.Date(2020, 3, 9, 0, 0, 0, 0, ny)},
+		}...)
+	}
+
+	for _, c := range cases {
+		w := &FileWriter{RotateInterval: c.Interval}
+		if next := w.nextRotation(c.Now); !next.Equal(c.Next) {
+			t.Errorf("nextRotation(%v) of %v want %v, got %v", c.Now, c.Interval, c.Next, next)
+		}
+	}
+}
+
+func TestFileWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := timeNow()
+	for i, name := range []string{"file-age.1.log", "file-age.2.log.gz", "file-age.3.log", "other.4.log"} {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte("leftover\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-time.Duration(48-i*12) * time.Hour)
+		if err := os.Chtimes(filename, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := &FileWriter{
+		Filename:   filepath.Join(dir, "file-age.log"),
+		TimeFormat: TimeFormatUnixMs,
+		MaxAge:     30 * time.Hour,
+	}
+	defer w.Close()
+
+	// the leftovers are cleaned up at startup, a zero MaxBackups retains all log files within MaxAge
+	if _, err := wlprintf(w, InfoLevel, "hello file writer!\n"); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	waitFiles(t, filepath.Join(dir, "file-age.[12].*"), func(m []string) bool { return len(m) == 0 })
+	for _, name := range []string{"file-age.3.log", "other.4.log", filepath.Base(w.file.Name())} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("file %s within max age must be retained: %+v", name, err)
+		}
+	}
+}
+
+func TestFileWriterMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	now := timeNow()
+	for i := 0; i < 4; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("file-total.%d.log", i))
+		if i%2 == 1 {
+			filename += ".gz"
+		}
+		if err := os.WriteFile(filename, bytes.Repeat([]byte("x"), 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(time.Duration(i-4) * time.Minute)
+		if err := os.Chtimes(filename, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := &FileWriter{
+		Filename:     filepath.Join(dir, "file-total.log"),
+		TimeFormat:   TimeFormatUnixMs,
+		MaxBackups:   3,
+		MaxTotalSize: 250,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello file writer!\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	// the current file and the 2 newest backups are within 250 bytes
+	waitFiles(t, filepath.Join(dir, "file-total.[01].*"), func(m []string) bool { return len(m) == 0 })
+	for _, name := range []string{"file-total.2.log", "file-total.3.log.gz", filepath.Base(w.file.Name())} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("file %s within max total size must be retained: %+v", name, err)
+		}
+	}
+}
+
+func TestFileWriterRename(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file-rename.log")
+	// the symlink left by the non-Rename mode
+	if err := os.WriteFile(filepath.Join(dir, "file-rename.old.log"), []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("file-rename.old.log", filename); err != nil {
+		t.Skipf("symlink is not supported: %+v", err)
+	}
+
+	w := &FileWriter{
+		Filename:   filename,
+		MaxBackups: 10,
+		Rename:     true,
+	}
+	defer w.Close()
+
+	for i, s := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+		if i < 2 {
+			if err := w.Rotate(); err != nil {
+				t.Fatalf("file writer rotate error: %+v", err)
+			}
+		}
+	}
+
+	if fi, err := os.Lstat(filename); err != nil || !fi.Mode().IsRegular() {
+		t.Fatalf("file writer must write to a regular file: %v %+v", fi, err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "c\n" {
+		t.Fatalf("file writer must write to filename: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "file-rename.old.log")); string(data) != "old\n" {
+		t.Fatalf("file writer must not write to the symlink target: %q", data)
+	}
+
+	// the backups renamed within the same second do not overwrite each other
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatalf("file writer backups error: %+v", err)
+	}
+	var contents []string
+	for _, info := range backups {
+		if info.Name() != "file-rename.old.log" {
+			data, _ := os.ReadFile(filepath.Join(dir, info.Name()))
+			contents = append(contents, string(data))
+		}
+	}
+	if strings.Join(contents, "") != "a\nb\n" {
+		t.Fatalf("file writer must rename filename to backups: %q", contents)
+	}
+}
+
+func TestFileWriterNumberedBackups(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:        filepath.Join(dir, "file-numbered.log"),
+		MaxBackups:      2,
+		Rename:          true,
+		NumberedBackups: true,
+		Compress:        true,
+	}
+	defer w.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(w, "%d\n", i); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+		if i < 3 {
+			if err := w.Rotate(); err != nil {
+				t.Fatalf("file writer rotate error: %+v", err)
+			}
+		}
+	}
+
+	waitFiles(t, w.Filename+".*", func(m []string) bool {
+		return len(m) == 2 && m[0] == w.Filename+".1.gz" && m[1] == w.Filename+".2.gz"
+	})
+	if data, _ := os.ReadFile(w.Filename); string(data) != "3\n" {
+		t.Fatalf("file writer must write to filename: %q", data)
+	}
+	for i, want := range []string{"2\n", "1\n"} {
+		file, err := os.Open(fmt.Sprintf("%s.%d.gz", w.Filename, i+1))
+		if err != nil {
+			t.Fatalf("open numbered backup error: %+v", err)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("gzip reader error: %+v", err)
+		}
+		data, err := io.ReadAll(zr)
+		file.Close()
+		if err != nil || string(data) != want {
+			t.Fatalf("numbered backup %d content want %q, got %q %v", i+1, want, data, err)
+		}
+	}
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename: filepath.Join(dir, "file-reopen.log"),
+		MaxSize:  20,
+		Rename:   true,
+	}
+	defer w.Close()
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+
+	// renamed by logrotate, then reopened by postrotate
+	write("a\n")
+	if err := os.Rename(w.Filename, w.Filename+".1"); err != nil {
+		t.Fatal(err)
+	}
+	write("b\n")
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("file writer reopen error: %+v", err)
+	}
+	write("c\n")
+	if got := read("file-reopen.log.1") + read("file-reopen.log"); got != "a\nb\nc\n" {
+		t.Fatalf("file writer must reopen filename: %q", got)
+	}
+
+	// renamed by logrotate without postrotate
+	if err := os.Rename(w.Filename, w.Filename+".2"); err != nil {
+		t.Fatal(err)
+	}
+	w.checkat = 0
+	write("d\n")
+	if got := read("file-reopen.log.2") + read("file-reopen.log"); got != "c\nd\n" {
+		t.Fatalf("file writer must detect the renamed filename: %q", got)
+	}
+
+	// truncated by copytruncate
+	write("0123456789abc\n")
+	if err := os.Truncate(w.Filename, 0); err != nil {
+		t.Fatal(err)
+	}
+	w.checkat = 0
+	write("0123456789\n")
+	if got := read("file-reopen.log"); got != "0123456789\n" {
+		t.Fatalf("file writer must not rotate the truncated filename: %q", got)
+	}
+}
+
+func TestFileWriterReopenOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename: filepath.Join(dir, "file-signal.log"),
+		Rename:   true,
+	}
+	defer w.Close()
+	stop := w.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	if _, err := w.Write([]byte("a\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	if err := os.Rename(w.Filename, w.Filename+".1"); err != nil {
+		t.Fatal(err)
+	}
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	waitFiles(t, w.Filename, func(m []string) bool { return len(m) == 1 })
+	if _, err := w.Write([]byte("b\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	if data, _ := os.ReadFile(w.Filename); string(data) != "b\n" {
+		t.Fatalf("file writer must reopen filename on signal: %q", data)
+	}
+}
+
+func TestFileWriterBuffer(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:   filepath.Join(dir, "file-buffer.log"),
+		MaxBackups: 10,
+		BufferSize: 16,
+		Rename:     true,
+	}
+	defer w.Close()
+
+	write := func(s string) {
+		t.Helper()
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(name)
+		return string(data)
+	}
+
+	write("0123456789\n")
+	if got := read(w.Filename); got != "" {
+		t.Fatalf("file writer must buffer the writes: %q", got)
+	}
+	write("abcdef\n")
+	if got := read(w.Filename); got != "0123456789\n" {
+		t.Fatalf("file writer must write the buffer before it overflows: %q", got)
+	}
+	write("a line longer than the buffer\n")
+	if got := read(w.Filename); got != "0123456789\nabcdef\na line longer than the buffer\n" {
+		t.Fatalf("file writer must write the long line in order: %q", got)
+	}
+
+	// flushed by the registered flusher
+	write("flush\n")
+	if err := Flush(context.Background()); err != nil {
+		t.Fatalf("flush error: %+v", err)
+	}
+	if got := read(w.Filename); !strings.HasSuffix(got, "flush\n") {
+		t.Fatalf("file writer must be flushed by Flush: %q", got)
+	}
+
+	// flushed by rotation
+	write("rotate\n")
+	backup := w.Filename + ".rotated"
+	if err := os.Rename(w.Filename, backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("file writer reopen error: %+v", err)
+	}
+	if got := read(backup); !strings.HasSuffix(got, "flush\nrotate\n") {
+		t.Fatalf("file writer must be flushed before reopen: %q", got)
+	}
+
+	write("close\n")
+	if err := w.Close(); err != nil {
+		t.Fatalf("file writer close error: %+v", err)
+	}
+	if got := read(w.Filename); got != "close\n" {
+		t.Fatalf("file writer must be flushed by Close: %q", got)
+	}
+	flushers.mu.Lock()
+	for _, f := range flushers.list {
+		if f == w {
+			t.Errorf("file writer must be unregistered by Close")
+		}
+	}
+	flushers.mu.Unlock()
+}
+
+func TestFileWriterBufferRotate(t *testing.T) {
+	dir := t.TempDir()
+	w := &FileWriter{
+		Filename:   filepath.Join(dir, "file-buffer-rotate.log"),
+		TimeFormat: TimeFormatUnixMs,
+		MaxSize:    20,
+		MaxBackups: 10,
+		BufferSize: 1024,
+	}
+	defer w.Close()
+
+	for _, s := range []string{"0123456789\n", "abcdefghij\n", "ABCDEFGHIJ\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("file writer error: %+v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("file writer flush error: %+v", err)
+	}
+
+	var contents []string
+	matches, _ := filepath.Glob(filepath.Join(dir, "file-buffer-rotate.*.log"))
+	for _, name := range matches {
+		if data, _ := os.ReadFile(name); len(data) != 0 {
+			contents = append(contents, string(data))
+		}
+	}
+	if strings.Join(contents, "|") != "0123456789\nabcdefghij\n|ABCDEFGHIJ\n" {
+		t.Fatalf("file writer must flush the buffer before rotation: %q", contents)
+	}
+}
+
+func TestFileWriterFlushInterval(t *testing.T) {
+	w := &FileWriter{
+		Filename:      filepath.Join(t.TempDir(), "file-flush.log"),
+		BufferSize:    1024,
+		FlushInterval: 10 * time.Millisecond,
+		Rename:        true,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if data, _ := os.ReadFile(w.Filename); string(data) == "hello\n" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("file writer must flush the buffer periodically")
+}
+
+func TestFileWriterSync(t *testing.T) {
+	dirty := func(w *FileWriter) bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.dirty
+	}
+
+	w := &FileWriter{
+		Filename:   filepath.Join(t.TempDir(), "file-sync-level.log"),
+		BufferSize: 1024,
+		SyncLevel:  ErrorLevel,
+		Rename:     true,
+	}
+	defer w.Close()
+
+	if _, err := wlprintf(w, WarnLevel, "warn\n"); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	if data, _ := os.ReadFile(w.Filename); len(data) != 0 {
+		t.Fatalf("file writer must buffer the entries below SyncLevel: %q", data)
+	}
+	if _, err := wlprintf(w, ErrorLevel, "error\n"); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	if data, _ := os.ReadFile(w.Filename); string(data) != "warn\nerror\n" || dirty(w) {
+		t.Fatalf("file writer must flush and sync the entries at SyncLevel: %q", data)
+	}
+
+	w = &FileWriter{
+		Filename:     filepath.Join(t.TempDir(), "file-sync-interval.log"),
+		SyncInterval: 10 * time.Millisecond,
+		Rename:       true,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("file writer error: %+v", err)
+	}
+	for i := 0; i < 100 && dirty(w); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if dirty(w) {
+		t.Fatalf("file writer must sync periodically")
+	}
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Flusher defines an interface to a writer which buffers entries.
+type Flusher interface {
+	// Flush writes the buffered entries to the underlying writer.
+	Flush() error
+}
+
+// FlushTimeout specifies the timeout of flushing the registered writers
+// before a fatal entry exits and a panic entry panics.
+var FlushTimeout = 5 * time.Second
+
+var flushers struct {
+	mu   sync.Mutex
+	list []Flusher
+}
+
+// RegisterFlusher registers f to be flushed by Flush, Shutdown, and the fatal and panic entries.
+// The writers which buffer entries, such as AsyncWriter, register themselves.
+func RegisterFlusher(f Flusher) {
+	flushers.mu.Lock()
+	defer flushers.mu.Unlock()
+	for _, f1 := range flushers.list {
+		if f1 == f {
+			return
+		}
+	}
+	flushers.list = append(flushers.list, f)
+}
+
+// UnregisterFlusher unregisters f, it is called by the writers when closed.
+func UnregisterFlusher(f Flusher) {
+	flushers.mu.Lock()
+	defer flushers.mu.Unlock()
+	for i, f1 := range flushers.list {
+		if f1 == f {
+			flushers.list = append(flushers.list[:i], flushers.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// Flush flushes the registered writers, it returns ctx.Err() if ctx is done before finished.
+func Flush(ctx context.Context) error {
+	flushers.mu.Lock()
+	list := append([]Flusher(nil), flushers.list...)
+	flushers.mu.Unlock()
+	if len(list) == 0 {
+		return nil
+	}
+
+	return flushWithContext(ctx, func() (err error) {
+		for _, f := range list {
+			if err1 := f.Flush(); err1 != nil {
+				err = err1
+			}
+		}
+		return
+	})
+}
+
+// Shutdown flushes and closes the registered writers, and unregisters them.
+// It returns ctx.Err() if ctx is done before finished.
+func Shutdown(ctx context.Context) error {
+	flushers.mu.Lock()
+	list := flushers.list
+	flushers.list = nil
+	flushers.mu.Unlock()
+
+	return flushWithContext(ctx, func() (err error) {
+		for _, f := range list {
+			if err1 := f.Flush(); err1 != nil {
+				err = err1
+			}
+		}
+		for _, f := range list {
+			if closer, ok := f.(io.Closer); ok {
+				if err1 := closer.Close(); err1 != nil {
+					err = err1
+				}
+			}
+		}
+		return
+	})
+}
+
+func flushWithContext(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flushOnExit flushes the registered writers with FlushTimeout before exiting or panicking.
+func flushOnExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), FlushTimeout)
+	defer cancel()
+	_ = Flush(ctx)
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testFlusher struct {
+	flushed int
+	closed  int
+	block   chan struct{}
+}
+
+func (f *testFlusher) Flush() error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.flushed++
+	return nil
+}
+
+func (f *testFlusher) Close() error {
+	f.closed++
+	return nil
+}
+
+func TestFlushShutdown(t *testing.T) {
+	flushers.mu.Lock()
+	saved := flushers.list
+	flushers.list = nil
+	flushers.mu.Unlock()
+	defer func() {
+		flushers.mu.Lock()
+		flushers.list = saved
+		flushers.mu.Unlock()
+	}()
+
+	f := &testFlusher{}
+	RegisterFlusher(f)
+	RegisterFlusher(f)
+
+	if err := Flush(context.Background()); err != nil || f.flushed != 1 {
+		t.Fatalf("Flush should flush the registered writer once: %+v, %d", err, f.flushed)
+	}
+	if err := Shutdown(context.Background()); err != nil || f.flushed != 2 || f.closed != 1 {
+		t.Fatalf("Shutdown should flush and close the registered writer: %+v, %d, %d", err, f.flushed, f.closed)
+	}
+	if err := Flush(context.Background()); err != nil || f.flushed != 2 {
+		t.Fatalf("Shutdown should unregister the writer: %+v, %d", err, f.flushed)
+	}
+
+	f.block = make(chan struct{})
+	RegisterFlusher(f)
+	defer UnregisterFlusher(f)
+	defer close(f.block)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Flush(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Flush should return the error of context: %+v", err)
+	}
+}
+
+func TestLoggerExit(t *testing.T) {
+	var buf bytes.Buffer
+	w := &AsyncWriter{
+		ChannelSize: 100,
+		Writer: WriterFunc(func(e *Entry) (int, error) {
+			time.Sleep(time.Millisecond)
+			return buf.Write(e.buf)
+		}),
+	}
+	defer w.Close()
+
+	var code int
+	var output string
+	logger := Logger{
+		Level: InfoLevel,
+		Exit: func(c int) {
+			code, output = c, buf.String()
+		},
+		Writer: w,
+	}
+	for i := 0; i < 10; i++ {
+		logger.Info().Int("i", i).Msg("hello")
+	}
+	logger.Fatal().Msg("bye")
+
+	if code != 255 || strings.Count(output, "\n") != 11 || !strings.Contains(output, `"level":"fatal","message":"bye"`) {
+		t.Fatalf("fatal entry should flush the async writer before exit: %d, %q", code, output)
+	}
+}
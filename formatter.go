@@ -36,7 +36,25 @@ func (args *FormatterArgs) Get(key string) (value string) {
 	return
 }
 
-func formatterArgsPos(key string) (pos int) {
+func formatterArgsPos(key string, names *FieldNames) (pos int) {
+	if names != nil && key != "" {
+		switch key {
+		case names.Time:
+			return 1
+		case names.Level:
+			return 2
+		case names.Caller:
+			return 3
+		case names.CallerFunc:
+			return 4
+		case names.Goid:
+			return 5
+		case names.Stack:
+			return 6
+		case names.Message:
+			return 7
+		}
+	}
 	switch {
 	case key == "time" || key == TimeKey:
 		pos = 1
@@ -56,8 +74,8 @@ func formatterArgsPos(key string) (pos int) {
 	return
 }
 
-// parseFormatterArgs extracts json string to json items
-func parseFormatterArgs(json []byte, args *FormatterArgs) {
+// parseFormatterArgs extracts json string to json items, names specifies the optional names of builtin fields.
+func parseFormatterArgs(json []byte, args *FormatterArgs, names *FieldNames) {
 	// Pre-allocate KeyValues slice to a reasonable capacity.
 	// This prevents a race condition that can lead to memory corruption
 	// when append is called on a nil slice from multiple goroutines.
@@ -109,7 +127,7 @@ func parseFormatterArgs(json []byte, args *FormatterArgs) {
 			str = jsonUnescape(str[1:len(str)-1], str[:0])
 			typ = 's'
 		}
-		pos := formatterArgsPos(b2s(key))
+		pos := formatterArgsPos(b2s(key), names)
 		if pos == 0 && args.Time == "" {
 			pos = 1
 		}
@@ -14,7 +14,7 @@ func TestFormatterParse(t *testing.T) {
 
 	for _, s := range jsons {
 		var args FormatterArgs
-		parseFormatterArgs([]byte(s), &args)
+		parseFormatterArgs([]byte(s), &args, nil)
 		t.Logf("%+v", args)
 		t.Logf("foo=%v", args.Get("foo"))
 	}
@@ -28,7 +28,7 @@ func TestFormatterArgsParse(t *testing.T) {
 	var json = `{"time":"` + timestamp + `","level":"` + level + `","category":"` + category + `","message":"` + msg + `"}`
 
 	var args FormatterArgs
-	parseFormatterArgs([]byte(json), &args)
+	parseFormatterArgs([]byte(json), &args, nil)
 	if args.Time != timestamp {
 		t.Fatalf("Failed to parse timestamp: %s != %s", args.Time, timestamp)
 	}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -56,10 +57,19 @@ func (w *JournalWriter) WriteEntry(e *Entry) (n int, err error) {
 	b0 := bbpool.Get().(*bb)
 	b0.B = b0.B[:0]
 	defer bbpool.Put(b0)
-	b0.B = append(b0.B, e.buf...)
+	json := e.buf
+	if b := e.jsonbuf(); b != nil {
+		defer func() {
+			if cap(b.B) <= bbcap {
+				bbpool.Put(b)
+			}
+		}()
+		json = b.B
+	}
+	b0.B = append(b0.B, json...)
 
 	var args FormatterArgs
-	parseFormatterArgs(b0.B, &args)
+	parseFormatterArgs(b0.B, &args, e.names)
 	if args.Time == "" {
 		return
 	}
@@ -111,6 +121,9 @@ func (w *JournalWriter) WriteEntry(e *Entry) (n int, err error) {
 		priority = "0" // Emergency
 	default:
 		priority = "5" // Notice
+		if c := customLevel(e.Level); c != nil && 0 <= c.Priority && c.Priority <= 7 {
+			priority = strconv.Itoa(c.Priority)
+		}
 	}
 	print(false, "PRIORITY", priority)
 
@@ -137,7 +150,7 @@ func (w *JournalWriter) WriteEntry(e *Entry) (n int, err error) {
 		print(true, kv.Key, kv.Value)
 	}
 
-	print(false, "JSON", b2s(e.buf))
+	print(false, "JSON", b2s(json))
 
 	// write
 	n, _, err = w.conn.WriteMsgUnix(b.B, nil, w.addr)
@@ -5,6 +5,7 @@ package log
 import (
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -58,3 +59,35 @@ func TestJournalWriterError(t *testing.T) {
 	_, _ = wlprintf(w, InfoLevel, "a long long long long message.\n")
 	w.Close()
 }
+
+func TestJournalWriterLogfmt(t *testing.T) {
+	sockname := t.TempDir() + "/journal.sock"
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sockname, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	defer conn.Close()
+
+	w := &JournalWriter{
+		JournalSocket: sockname,
+	}
+	defer w.Close()
+
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: LogfmtEncoding,
+		Writer:   w,
+	}
+	logger.Info().Str("foo", "bar baz").Msg("hello journal writer")
+
+	var data [4096]byte
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFromUnix(data[:])
+	if err != nil {
+		t.Fatalf("journal writer should send logfmt entry: %+v", err)
+	}
+	if s := string(data[:n]); !strings.Contains(s, "MESSAGE=hello journal writer\n") || !strings.Contains(s, "FOO=bar baz\n") {
+		t.Errorf("journal writer should decode logfmt entry: %q", s)
+	}
+}
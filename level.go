@@ -1,6 +1,16 @@
 package log
 
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
 // Level defines log levels.
+//
+// The levels less than 100 are ranked at 100 times of their values, so the
+// user-defined levels registered by RegisterLevel are ordered among them,
+// e.g. a level of 350 is greater than InfoLevel and less than WarnLevel.
 type Level uint32
 
 const (
@@ -51,13 +61,25 @@ func (l Level) String() (s string) {
 	case PanicLevel:
 		s = PanicLevelString
 	default:
-		s = "????"
+		if c := customLevel(l); c != nil {
+			s = c.Name
+		} else {
+			s = "????"
+		}
 	}
 	return
 }
 
 // ParseLevel converts a level string into a log Level value.
 func ParseLevel(s string) (level Level) {
+	level, _ = LookupLevel(s)
+	return
+}
+
+// LookupLevel converts a level string into a log Level value, the boolean is
+// false if s is not the name or abbreviation of a builtin or registered level.
+func LookupLevel(s string) (level Level, ok bool) {
+	ok = true
 	switch s {
 	case "trace", "Trace", "TRACE", "TRC":
 		level = TraceLevel
@@ -74,7 +96,99 @@ func ParseLevel(s string) (level Level) {
 	case "panic", "Panic", "PANIC", "PNC":
 		level = PanicLevel
 	default:
-		level = noLevel
+		level, ok = noLevel, false
+		m, _ := customLevels.Load().(map[Level]*CustomLevel)
+		for l, c := range m {
+			if strings.EqualFold(s, c.Name) || (c.Abbr != "" && s == c.Abbr) {
+				level, ok = l, true
+				break
+			}
+		}
 	}
 	return
 }
+
+// rank returns the ordering of level.
+func (l Level) rank() uint32 {
+	if l < 100 {
+		return uint32(l) * 100
+	}
+	return uint32(l)
+}
+
+// Compare returns -1, 0 or +1 if l is ordered before, equal to or after level,
+// the user-defined levels are ordered among the builtin levels.
+func (l Level) Compare(level Level) int {
+	switch a, b := l.rank(), level.rank(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// CustomLevel defines the properties of a user-defined level.
+type CustomLevel struct {
+	// Name specifies the level name, it is used by String, ParseLevel and the level field.
+	Name string
+
+	// Abbr specifies the three letters abbreviation used by ConsoleWriter, e.g. "NTC".
+	Abbr string
+
+	// Color specifies the ANSI color used by ConsoleWriter, e.g. "\x1b[36m".
+	Color string
+
+	// Priority specifies the syslog and journal priority from 0 (emerg) to 7 (debug).
+	Priority int
+}
+
+var (
+	customLevels   atomic.Value // map[Level]*CustomLevel
+	customLevelsMu sync.Mutex
+)
+
+// RegisterLevel registers a user-defined level, it panics if level is less
+// than 100 or name is empty. It is intended to be called in init functions.
+//
+//	const NoticeLevel log.Level = 350
+//
+//	func init() {
+//		log.RegisterLevel(NoticeLevel, log.CustomLevel{Name: "notice", Abbr: "NTC", Color: "\x1b[36m", Priority: 5})
+//	}
+func RegisterLevel(level Level, custom CustomLevel) {
+	if level < 100 {
+		panic("log: RegisterLevel with level less than 100")
+	}
+	if custom.Name == "" {
+		panic("log: RegisterLevel with empty name")
+	}
+
+	customLevelsMu.Lock()
+	defer customLevelsMu.Unlock()
+
+	old, _ := customLevels.Load().(map[Level]*CustomLevel)
+	m := make(map[Level]*CustomLevel, len(old)+1)
+	for l, c := range old {
+		m[l] = c
+	}
+	m[level] = &custom
+	customLevels.Store(m)
+}
+
+// customLevel returns the registered properties of level, or nil.
+func customLevel(level Level) *CustomLevel {
+	m, _ := customLevels.Load().(map[Level]*CustomLevel)
+	return m[level]
+}
+
+// customLevelByName returns the registered properties of level name, or nil.
+func customLevelByName(name string) *CustomLevel {
+	m, _ := customLevels.Load().(map[Level]*CustomLevel)
+	for _, c := range m {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
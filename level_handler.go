@@ -0,0 +1,204 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelHandler is an http.Handler to view and change the levels of loggers at runtime.
+//
+// GET responds the levels of the loggers and their categories in JSON, e.g.
+//
+//	[{"logger":"default","level":"info"},{"logger":"default","category":"db","level":"debug"}]
+//
+// PUT changes the level by the query or JSON body parameters:
+//
+//	logger    the logger name, it uses "default" if empty
+//	category  the optional category name or glob pattern, see Logger.SetCategoryLevel
+//	level     the level name, e.g. "debug"
+//	ttl       the optional duration to revert the level automatically, e.g. "15m"
+//
+// The handler does not authenticate requests, so it should be mounted behind an
+// authenticated admin endpoint.
+type LevelHandler struct {
+	// Loggers specifies the loggers by name, DefaultLogger is served as "default" if absent.
+	Loggers map[string]*Logger
+
+	mu      sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+type levelRevert struct {
+	timer  *time.Timer
+	at     time.Time
+	revert func()
+}
+
+type levelHandlerItem struct {
+	Logger   string `json:"logger"`
+	Category string `json:"category,omitempty"`
+	Level    string `json:"level"`
+	RevertAt string `json:"revert_at,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+}
+
+func (h *LevelHandler) logger(name string) *Logger {
+	if name == "" {
+		name = "default"
+	}
+	if logger, ok := h.Loggers[name]; ok {
+		return logger
+	}
+	if name == "default" {
+		return &DefaultLogger
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *LevelHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		var item levelHandlerItem
+		if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(req.Body).Decode(&item); err != nil {
+				http.Error(rw, "invalid json body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else {
+			item.Logger = req.FormValue("logger")
+			item.Category = req.FormValue("category")
+			item.Level = req.FormValue("level")
+			item.TTL = req.FormValue("ttl")
+		}
+		if err := h.set(item); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		rw.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(h.list())
+}
+
+func (h *LevelHandler) set(item levelHandlerItem) error {
+	if item.Logger == "" {
+		item.Logger = "default"
+	}
+	logger := h.logger(item.Logger)
+	if logger == nil {
+		return errors.New("unknown logger: " + item.Logger)
+	}
+	level := ParseLevel(item.Level)
+	if level == noLevel {
+		return errors.New("invalid level: " + item.Level)
+	}
+	var ttl time.Duration
+	if item.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(item.TTL); err != nil || ttl <= 0 {
+			return errors.New("invalid ttl: " + item.TTL)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := item.Logger + "\x00" + item.Category
+	pending := h.reverts[key]
+	if pending != nil {
+		pending.timer.Stop()
+		delete(h.reverts, key)
+	}
+
+	// the revert restores the level before the first pending change
+	var revert func()
+	switch {
+	case pending != nil:
+		revert = pending.revert
+	case item.Category == "":
+		old := logger.loadLevel()
+		revert = func() { logger.SetLevel(old) }
+	default:
+		pattern := item.Category
+		if old, ok := logger.categoryRule(pattern); ok {
+			revert = func() { logger.SetCategoryLevel(pattern, old) }
+		} else {
+			revert = func() { logger.unsetCategoryLevel(pattern) }
+		}
+	}
+
+	if item.Category == "" {
+		logger.SetLevel(level)
+	} else {
+		logger.SetCategoryLevel(item.Category, level)
+	}
+
+	if ttl > 0 {
+		r := &levelRevert{at: timeNow().Add(ttl), revert: revert}
+		r.timer = time.AfterFunc(ttl, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.reverts[key] == r {
+				delete(h.reverts, key)
+				r.revert()
+			}
+		})
+		if h.reverts == nil {
+			h.reverts = make(map[string]*levelRevert)
+		}
+		h.reverts[key] = r
+	}
+
+	return nil
+}
+
+func (h *LevelHandler) list() (items []levelHandlerItem) {
+	names := make([]string, 0, len(h.Loggers)+1)
+	for name := range h.Loggers {
+		names = append(names, name)
+	}
+	if _, ok := h.Loggers["default"]; !ok {
+		names = append(names, "default")
+	}
+	sort.Strings(names)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revertAt := func(name, category string) string {
+		if r, ok := h.reverts[name+"\x00"+category]; ok {
+			return r.at.Format(time.RFC3339)
+		}
+		return ""
+	}
+	for _, name := range names {
+		logger := h.logger(name)
+		items = append(items, levelHandlerItem{
+			Logger:   name,
+			Level:    logger.loadLevel().String(),
+			RevertAt: revertAt(name, ""),
+		})
+		for _, category := range logger.Categories() {
+			items = append(items, levelHandlerItem{
+				Logger:   name,
+				Category: category,
+				Level:    logger.Categorized(category).loadLevel().String(),
+				RevertAt: revertAt(name, category),
+			})
+		}
+	}
+	return
+}
+
+var _ http.Handler = (*LevelHandler)(nil)
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelHandler(t *testing.T) {
+	logger := &Logger{Level: InfoLevel}
+	logger.Categorized("db")
+	logger.Categorized("db.pool")
+
+	handler := &LevelHandler{Loggers: map[string]*Logger{"app": logger}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	list := func() (items []levelHandlerItem) {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("get error: %+v", err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+			t.Fatalf("decode error: %+v", err)
+		}
+		return
+	}
+	put := func(contentType, query, body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPut, server.URL+"?"+query, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("put error: %+v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	items := list()
+	if len(items) != 4 {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	if items[0].Logger != "app" || items[0].Level != "info" || items[1].Category != "db" || items[2].Category != "db.pool" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+	if items[3].Logger != "default" {
+		t.Errorf("default logger should be listed: %+v", items)
+	}
+
+	if resp := put("", "logger=app&level=warn", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("put logger level status: %d", resp.StatusCode)
+	}
+	if logger.Level != WarnLevel {
+		t.Errorf("logger level should be warn: %s", logger.Level)
+	}
+
+	resp := put("application/json", "", `{"logger":"app","category":"db","level":"debug","ttl":"100ms"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("put category level status: %d", resp.StatusCode)
+	}
+	if level := logger.Categorized("db.pool").Level; level != DebugLevel {
+		t.Errorf("category level should be debug: %s", level)
+	}
+	if items := list(); items[1].Level != "debug" || items[1].RevertAt == "" {
+		t.Errorf("category should have revert_at: %+v", items[1])
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if level := logger.Categorized("db.pool").loadLevel(); level != WarnLevel {
+		t.Errorf("category level should be reverted to the inherited level of logger: %s", level)
+	}
+	if _, ok := logger.categoryRule("db"); ok {
+		t.Errorf("category rule should be removed after revert")
+	}
+	if items := list(); items[1].RevertAt != "" {
+		t.Errorf("category should not have revert_at: %+v", items[1])
+	}
+}
+
+func TestLevelHandlerRevertOriginal(t *testing.T) {
+	logger := &Logger{Level: InfoLevel}
+	handler := &LevelHandler{Loggers: map[string]*Logger{"app": logger}}
+
+	if err := handler.set(levelHandlerItem{Logger: "app", Level: "debug", TTL: "1h"}); err != nil {
+		t.Fatalf("set error: %+v", err)
+	}
+	if err := handler.set(levelHandlerItem{Logger: "app", Level: "trace", TTL: "50ms"}); err != nil {
+		t.Fatalf("set error: %+v", err)
+	}
+	if logger.loadLevel() != TraceLevel {
+		t.Errorf("logger level should be trace: %s", logger.Level)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if level := logger.loadLevel(); level != InfoLevel {
+		t.Errorf("logger level should be reverted to the original info: %s", level)
+	}
+}
+
+func TestLevelHandlerErrors(t *testing.T) {
+	handler := &LevelHandler{Loggers: map[string]*Logger{"app": {Level: InfoLevel}}}
+
+	cases := []struct {
+		Method string
+		Query  string
+		Status int
+	}{
+		{http.MethodPut, "logger=app&level=verbose", http.StatusBadRequest},
+		{http.MethodPut, "logger=none&level=info", http.StatusBadRequest},
+		{http.MethodPut, "logger=app&level=info&ttl=soon", http.StatusBadRequest},
+		{http.MethodDelete, "logger=app", http.StatusMethodNotAllowed},
+	}
+
+	for _, c := range cases {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(c.Method, "/?"+c.Query, nil))
+		if rw.Code != c.Status {
+			t.Errorf("%s %s status %d, want %d", c.Method, c.Query, rw.Code, c.Status)
+		}
+	}
+}
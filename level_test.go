@@ -1,6 +1,8 @@
 package log
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -26,5 +28,95 @@ func TestLevelParse(t *testing.T) {
 		if v := c.Level.String(); v != c.String {
 			t.Errorf("%T.String() must return %#v, not %#v", c.Level, c.String, v)
 		}
+		if v, ok := LookupLevel(c.String); v != c.Level || ok != (c.Level != noLevel) {
+			t.Errorf("LookupLevel(%#v) must return %#v, %v, not %#v, %v", c.String, c.Level, c.Level != noLevel, v, ok)
+		}
+	}
+}
+
+const (
+	testNoticeLevel Level = 350
+	testAuditLevel  Level = 900
+)
+
+func init() {
+	RegisterLevel(testNoticeLevel, CustomLevel{Name: "notice", Abbr: "NTC", Color: "\x1b[36m", Priority: 5})
+	RegisterLevel(testAuditLevel, CustomLevel{Name: "audit", Abbr: "AUD", Priority: 6})
+}
+
+func TestCustomLevel(t *testing.T) {
+	if s := testNoticeLevel.String(); s != "notice" {
+		t.Fatalf("custom level String() must return %#v, not %#v", "notice", s)
+	}
+	for _, s := range []string{"notice", "NOTICE", "NTC"} {
+		if level := ParseLevel(s); level != testNoticeLevel {
+			t.Fatalf("ParseLevel(%#v) must return %#v, not %#v", s, testNoticeLevel, level)
+		}
+	}
+	if !(InfoLevel.rank() < testNoticeLevel.rank() && testNoticeLevel.rank() < WarnLevel.rank()) {
+		t.Fatalf("custom level must be ordered between info and warn")
+	}
+	if InfoLevel.Compare(testNoticeLevel) != -1 || testNoticeLevel.Compare(WarnLevel) != -1 || testAuditLevel.Compare(ErrorLevel) != 1 || testNoticeLevel.Compare(testNoticeLevel) != 0 {
+		t.Fatalf("Compare must order custom levels among builtin levels")
+	}
+	if level, ok := LookupLevel("NTC"); level != testNoticeLevel || !ok {
+		t.Fatalf("LookupLevel(%#v) must return %#v, true, not %#v, %v", "NTC", testNoticeLevel, level, ok)
+	}
+
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+	}
+
+	logger.WithLevel(testNoticeLevel).Msg("hello notice")
+	if s := b.String(); !strings.Contains(s, `"level":"notice","message":"hello notice"`) {
+		t.Fatalf("custom level must be written: %s", s)
+	}
+
+	b.Reset()
+	logger.SetLevel(testNoticeLevel)
+	logger.Info().Msg("hello info")
+	logger.WithLevel(testNoticeLevel).Msg("hello notice")
+	logger.Warn().Msg("hello warn")
+	if s := b.String(); strings.Contains(s, "hello info") || !strings.Contains(s, "hello notice") || !strings.Contains(s, "hello warn") {
+		t.Fatalf("custom level must filter entries: %s", s)
+	}
+
+	b.Reset()
+	logger.SetLevel(WarnLevel)
+	logger.WithLevel(testNoticeLevel).Msg("hello notice")
+	logger.WithLevel(testAuditLevel).Msg("hello audit")
+	if s := b.String(); strings.Contains(s, "hello notice") || !strings.Contains(s, `"level":"audit"`) {
+		t.Fatalf("custom level must be filtered by built-in levels: %s", s)
+	}
+
+	b.Reset()
+	logger.SetLevel(InfoLevel)
+	logger.Writer = &ConsoleWriter{Writer: &b}
+	logger.WithLevel(testNoticeLevel).Msg("hello console notice")
+	if s := b.String(); !strings.Contains(s, " NTC > hello console notice") {
+		t.Fatalf("console writer must use custom level abbreviation: %s", s)
+	}
+}
+
+func TestCustomLevelMultiLevelWriter(t *testing.T) {
+	var info, warn, errs bytes.Buffer
+	logger := Logger{
+		Level: InfoLevel,
+		Writer: &MultiLevelWriter{
+			InfoWriter:  &IOWriter{Writer: &info},
+			WarnWriter:  &IOWriter{Writer: &warn},
+			ErrorWriter: &IOWriter{Writer: &errs},
+		},
+	}
+
+	logger.WithLevel(testNoticeLevel).Msg("hello notice")
+	logger.WithLevel(testAuditLevel).Msg("hello audit")
+	if !strings.Contains(info.String(), "hello notice") || strings.Contains(warn.String(), "hello notice") {
+		t.Fatalf("notice must be routed as info: info=%s warn=%s", info.String(), warn.String())
+	}
+	if !strings.Contains(errs.String(), "hello audit") {
+		t.Fatalf("audit must be routed as no level: %s", errs.String())
 	}
 }
@@ -0,0 +1,309 @@
+package log
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// logfmtAppendKey appends the key of a logfmt field to dst, the bytes which are
+// not allowed in logfmt keys are replaced with '_'.
+func logfmtAppendKey(dst []byte, key string) []byte {
+	if len(dst) != 0 {
+		dst = append(dst, ' ')
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			n := len(dst)
+			dst = append(dst, key...)
+			for j := n + i; j < len(dst); j++ {
+				if c := dst[j]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+					dst[j] = '_'
+				}
+			}
+			return append(dst, '=')
+		}
+	}
+	dst = append(dst, key...)
+	return append(dst, '=')
+}
+
+// logfmtAppendValue appends the value of a logfmt field to dst, it is quoted if
+// empty or contains spaces, quotes, equal signs, control characters or invalid UTF-8.
+func logfmtAppendValue(dst []byte, s string) []byte {
+	if s == "" {
+		return append(dst, '"', '"')
+	}
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return logfmtAppendQuote(dst, s)
+		}
+		if c >= utf8.RuneSelf {
+			ascii = false
+		}
+	}
+	if !ascii && !utf8.ValidString(s) {
+		return logfmtAppendQuote(dst, s)
+	}
+	return append(dst, s...)
+}
+
+// logfmtAppendQuote appends the double-quoted s to dst, it escapes quotes, backslashes,
+// control characters and invalid UTF-8 bytes in Go syntax.
+func logfmtAppendQuote(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	j := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			if r, size := utf8.DecodeRuneInString(s[i:]); r != utf8.RuneError || size != 1 {
+				i += size
+				continue
+			}
+		} else if c >= ' ' && c != '"' && c != '\\' && c != 0x7f {
+			i++
+			continue
+		}
+		dst = append(dst, s[j:i]...)
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'x', hex[c>>4], hex[c&0xf])
+		}
+		i++
+		j = i
+	}
+	dst = append(dst, s[j:]...)
+	return append(dst, '"')
+}
+
+// logfmtAppendFloat appends f to dst, the non-finite values are written as NaN, +Inf and -Inf.
+func logfmtAppendFloat(dst []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(dst, "NaN"...)
+	case math.IsInf(f, 1):
+		return append(dst, "+Inf"...)
+	case math.IsInf(f, -1):
+		return append(dst, "-Inf"...)
+	}
+	return appendFloat(dst, f, bits)
+}
+
+// logfmtAppendJSONFields transcodes the JSON object members of src to logfmt fields,
+// the keys of nested objects are flattened with dots, e.g. "user.name".
+func logfmtAppendJSONFields(dst, src []byte, prefix []byte) []byte {
+	for len(src) != 0 {
+		switch src[0] {
+		case ',', ':', '{', '}', ' ', '\t', '\r', '\n':
+			src = src[1:]
+		default:
+			// key
+			n := jsonValueLen(src)
+			if n < 2 || src[0] != '"' {
+				return dst
+			}
+			key := append(prefix, src[1:n-1]...)
+			if jsonHasEscape(src[1 : n-1]) {
+				key = jsonUnescape(src[1:n-1], prefix)
+			}
+			src = src[n:]
+			for len(src) != 0 && (src[0] == ':' || src[0] == ' ' || src[0] == '\t' || src[0] == '\r' || src[0] == '\n') {
+				src = src[1:]
+			}
+			if len(src) == 0 {
+				return dst
+			}
+			// value
+			dst, src = logfmtAppendJSON(dst, src, key)
+		}
+	}
+	return dst
+}
+
+// logfmtAppendJSON transcodes the JSON value at the beginning of src to a logfmt field of key,
+// and returns the rest of src.
+func logfmtAppendJSON(dst, src []byte, key []byte) ([]byte, []byte) {
+	if src[0] == '{' {
+		i := len(dst)
+		n := jsonValueLen(src)
+		dst = logfmtAppendJSONFields(dst, src[1:n], append(key, '.'))
+		if len(dst) == i {
+			// empty object
+			dst = logfmtAppendKey(dst, b2s(key))
+			dst = append(dst, "{}"...)
+		}
+		return dst, src[n:]
+	}
+
+	n := jsonValueLen(src)
+	if n == 0 {
+		n = 1
+	}
+	dst = logfmtAppendKey(dst, b2s(key))
+	switch val := src[:n]; val[0] {
+	case '"':
+		if n < 2 {
+			return logfmtAppendValue(dst, b2s(val[1:])), src[n:]
+		}
+		s := val[1 : n-1]
+		if !jsonHasEscape(s) {
+			return logfmtAppendValue(dst, b2s(s)), src[n:]
+		}
+		b := bbpool.Get().(*bb)
+		b.B = jsonUnescape(s, b.B[:0])
+		dst = logfmtAppendValue(dst, b2s(b.B))
+		if cap(b.B) <= bbcap {
+			bbpool.Put(b)
+		}
+	default:
+		// numbers, literals and arrays
+		dst = logfmtAppendValue(dst, b2s(val))
+	}
+	return dst, src[n:]
+}
+
+func jsonHasEscape(s []byte) bool {
+	for _, c := range s {
+		if c == '\\' {
+			return true
+		}
+	}
+	return false
+}
+
+// logfmtToJSON appends the JSON object of logfmt line src to dst for the writers
+// parsing JSON. The quoted values are written as strings, and the bare values are
+// written as is if they are valid JSON, or as strings otherwise.
+func logfmtToJSON(dst, src []byte) []byte {
+	dst = append(dst, '{')
+	first := true
+	for len(src) != 0 {
+		if c := src[0]; c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			src = src[1:]
+			continue
+		}
+		// key
+		i := 0
+		for i < len(src) && src[i] != '=' && src[i] > ' ' {
+			i++
+		}
+		key := src[:i]
+		src = src[i:]
+		if len(src) == 0 || src[0] != '=' {
+			continue
+		}
+		src = src[1:]
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst = logfmtAppendJSONString(dst, key)
+		dst = append(dst, ':')
+		// value
+		if len(src) != 0 && src[0] == '"' {
+			b := bbpool.Get().(*bb)
+			b.B, src = logfmtUnquote(b.B[:0], src[1:])
+			dst = logfmtAppendJSONString(dst, b.B)
+			if cap(b.B) <= bbcap {
+				bbpool.Put(b)
+			}
+			continue
+		}
+		i = 0
+		for i < len(src) && src[i] > ' ' {
+			i++
+		}
+		val := src[:i]
+		src = src[i:]
+		if logfmtIsJSON(val) {
+			dst = append(dst, val...)
+		} else {
+			dst = logfmtAppendJSONString(dst, val)
+		}
+	}
+	return append(dst, '}')
+}
+
+// logfmtUnquote appends the unescaped content of the quoted value at the beginning of src
+// to dst, and returns the rest of src after the closing quote.
+func logfmtUnquote(dst, src []byte) ([]byte, []byte) {
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '"' {
+			return dst, src[i+1:]
+		}
+		if c != '\\' || i+1 == len(src) {
+			dst = append(dst, c)
+			continue
+		}
+		i++
+		switch c = src[i]; c {
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'x':
+			if i+2 < len(src) {
+				if n, err := strconv.ParseUint(b2s(src[i+1:i+3]), 16, 8); err == nil {
+					dst = append(dst, byte(n))
+					i += 2
+					continue
+				}
+			}
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst, nil
+}
+
+// logfmtIsJSON reports whether the bare value b is a JSON number, literal, array or object.
+func logfmtIsJSON(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	switch b[0] {
+	case 't', 'f', 'n':
+		s := b2s(b)
+		return s == "true" || s == "false" || s == "null"
+	case '[', '{', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return json.Valid(b)
+	}
+	return false
+}
+
+// logfmtAppendJSONString appends s to dst as a JSON string.
+func logfmtAppendJSONString(dst, s []byte) []byte {
+	dst = append(dst, '"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c == '\n':
+			dst = append(dst, '\\', 'n')
+		case c == '\r':
+			dst = append(dst, '\\', 'r')
+		case c == '\t':
+			dst = append(dst, '\\', 't')
+		case c < ' ' || c == 0x7f:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, '"')
+}
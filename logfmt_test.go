@@ -0,0 +1,161 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:      InfoLevel,
+		TimeFormat: TimeFormatUnix,
+		Context:    NewContext(nil).Str("ctx", "value").Value(),
+		Encoding:   LogfmtEncoding,
+		Writer:     IOWriter{&buf},
+	}
+	logger.Info().
+		Str("str", "a \"quoted\"\tstring").
+		Str("empty", "").
+		Str("utf8", "中文").
+		Str("invalid", "中\xff\x01").
+		Bytes("bytes", []byte("a=b")).
+		Int("int", -42).
+		Uint64("uint64", 1<<64-1).
+		Float32("float32", 1.1).
+		Float64("nan", math.NaN()).
+		Bool("bool", true).
+		Dur("dur", 1500*time.Microsecond).
+		Err(errors.New("an error")).
+		AnErr("nil", nil).
+		Strs("strs", []string{"a", "b c"}).
+		Str("bad key=", "v").
+		Msg("hello logfmt")
+
+	s := buf.String()
+	if !strings.HasPrefix(s, "time=") || !strings.HasSuffix(s, "\n") || strings.Count(s, "\n") != 1 {
+		t.Fatalf("logfmt entry should be a line starting with time: %q", s)
+	}
+	want := ` level=info ctx=value str="a \"quoted\"\tstring" empty="" utf8=中文 invalid="中\xff\x01" bytes="a=b" int=-42 uint64=18446744073709551615 float32=1.1 nan=NaN bool=true dur=1.5 error="an error" nil=null strs="[\"a\",\"b c\"]" bad_key_=v message="hello logfmt"` + "\n"
+	if got := s[strings.IndexByte(s, ' '):]; got != want {
+		t.Errorf("logfmt entry mismatch\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+func TestLogfmtEncodingFlatten(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: LogfmtEncoding,
+		Writer:   IOWriter{&buf},
+	}
+	logger.Info().
+		Dict("user", NewContext(nil).Str("name", "jack").Dict("addr", NewContext(nil).Int("zip", 100).Value()).Value()).
+		Object("object", &cborTestObject{"rose", 18}).
+		Dict("empty", NewContext(nil).Value()).
+		RawJSON("raw", []byte(`{"k v":[null,true],"s":"a\nb"}`)).
+		Msg("")
+
+	want := ` level=info user.name=jack user.addr.zip=100 object.name=rose object.age=18 empty={} raw.k_v=[null,true] raw.s="a\nb"` + "\n"
+	if s := buf.String(); !strings.HasSuffix(s, want) {
+		t.Errorf("logfmt entry should flatten nested objects\nwant: %s\ngot:  %s", want, s)
+	}
+}
+
+func TestLogfmtEncodingRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: LogfmtEncoding,
+		Redactor: &Redactor{Keys: []string{"password", "user.token"}},
+		Writer:   IOWriter{&buf},
+	}
+	logger.Info().
+		Str("password", "secret").
+		Dict("user", NewContext(nil).Str("token", "abc").Value()).
+		Msg("login")
+
+	if s := buf.String(); strings.Contains(s, "secret") || strings.Contains(s, "abc") || !strings.Contains(s, `user.token=[REDACTED]`) {
+		t.Errorf("logfmt entry should be redacted: %s", s)
+	}
+}
+
+func TestLogfmtEncodingConsoleWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Encoding: LogfmtEncoding,
+		Writer:   &ConsoleWriter{Writer: &buf},
+	}
+	logger.Info().Str("foo", "bar").Msg("hello console")
+
+	if s := buf.String(); !strings.Contains(s, "INF") || !strings.Contains(s, "hello console") || !strings.Contains(s, "foo=bar") || strings.Contains(s, "message=") {
+		t.Errorf("console writer should decode logfmt entry: %q", s)
+	}
+}
+
+func TestLogfmtEncodingJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:      InfoLevel,
+		TimeFormat: TimeFormatUnix,
+		Encoding:   LogfmtEncoding,
+		Writer:     IOWriter{&buf},
+	}
+	logger.Info().
+		Str("str", "a \"quoted\"\tstring").
+		Str("invalid", "\x01").
+		Str("num", "12ab").
+		Int("int", -42).
+		Float64("nan", math.NaN()).
+		Bool("bool", true).
+		AnErr("nil", nil).
+		Strs("strs", []string{"a", "b c"}).
+		RawJSON("raw", []byte(`[null,true]`)).
+		Msg("hello logfmt")
+
+	line := logfmtToJSON(nil, buf.Bytes())
+	var m map[string]any
+	if err := json.Unmarshal(line, &m); err != nil {
+		t.Fatalf("logfmtToJSON should return valid json: %+v, line: %s", err, line)
+	}
+	delete(m, "time")
+	want := map[string]any{
+		"level":   "info",
+		"str":     "a \"quoted\"\tstring",
+		"invalid": "\x01",
+		"num":     "12ab",
+		"int":     float64(-42),
+		"nan":     "NaN",
+		"bool":    true,
+		"nil":     nil,
+		"strs":    `["a","b c"]`,
+		"raw":     []any{nil, true},
+		"message": "hello logfmt",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("logfmtToJSON mismatch\nwant: %v\ngot:  %s", want, line)
+	}
+}
+
+func BenchmarkLoggerLogfmt(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Encoding:   LogfmtEncoding,
+		Writer:     IOWriter{io.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Msgf("hello %s", "world")
+	}
+}
@@ -32,6 +32,23 @@ type Entry struct {
 	buf   []byte
 	Level Level
 	w     Writer
+	hooks []Hook
+
+	extractors []ContextExtractor
+	sampler    MessageSampler
+	redactor   *Redactor
+	redacting  *Redactor
+	names      *FieldNames
+	errchain   bool
+	hooking    bool
+	discarded  bool
+	enc        Encoding
+	encoded    int
+	errhandler ErrorHandler
+	exit       func(code int)
+	anyptrs    []unsafe.Pointer
+	parent     *Logger
+	dedup      bool
 }
 
 // Writer defines an entry writer interface.
@@ -101,11 +118,57 @@ type Logger struct {
 	// TimeLocation specifics that the location which TimeFormat used. It uses time.Local if empty.
 	TimeLocation *time.Location
 
+	// ErrorChain determines if errors are serialized as structured chains, which
+	// contain the message, type and stack trace of every wrapped or joined error.
+	ErrorChain bool
+
+	// StackLevel determines if adds the structured stack trace of the "stack" key
+	// for the entries at or above the level. It is disabled if empty.
+	StackLevel Level
+
+	// StackOptions specifies the options of structured stack traces added by StackLevel.
+	StackOptions StackOptions
+
 	// Context specifies an optional context of logger.
 	Context Context
 
+	// ContextExtractors specifies the extractors which add fields from a context.Context by Entry.Ctx.
+	ContextExtractors []ContextExtractor
+
+	// Sampler specifies an optional sampler of logger entries.
+	Sampler Sampler
+
+	// Hooks specifies the hooks which run on every entry before the message is appended.
+	Hooks []Hook
+
+	// Redactor specifies an optional redactor of sensitive fields.
+	Redactor *Redactor
+
+	// FieldNames specifies the names of builtin fields, it uses TimeKey, LevelKey,
+	// MessageKey, etc. if empty.
+	FieldNames *FieldNames
+
+	// Encoding specifies the wire format of entries, it uses JSONEncoding if empty.
+	// The Context of logger and the Context values of entries are JSON and transcoded.
+	Encoding Encoding
+
+	// ErrorHandler specifies the handler of errors returned by Writer.
+	// It uses DefaultErrorHandler if empty.
+	ErrorHandler ErrorHandler
+
+	// Exit specifies the function called by the fatal entries after the registered
+	// writers are flushed. It uses os.Exit if empty.
+	Exit func(code int)
+
+	// Clock specifies the source of entry times, it uses the system clock if empty.
+	// The times are formatted in TimeLocation, or the location of returned time if empty.
+	Clock func() time.Time
+
 	// Writer specifies the writer of output. It uses a wrapped os.Stderr Writer in if empty.
 	Writer Writer
+
+	// registry holds the categorized loggers and category level rules, it is created by the first use.
+	registry *categoryRegistry
 }
 
 // TimeFormatUnix defines a time format that makes time fields to be
@@ -122,7 +185,7 @@ const TimeFormatUnixWithMs = "\x03"
 
 // Trace starts a new message with trace level.
 func Trace() (e *Entry) {
-	if DefaultLogger.silent(TraceLevel) {
+	if DefaultLogger.silent(TraceLevel) || DefaultLogger.sampleout(TraceLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(TraceLevel)
@@ -138,7 +201,7 @@ func Trace() (e *Entry) {
 
 // Debug starts a new message with debug level.
 func Debug() (e *Entry) {
-	if DefaultLogger.silent(DebugLevel) {
+	if DefaultLogger.silent(DebugLevel) || DefaultLogger.sampleout(DebugLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(DebugLevel)
@@ -154,7 +217,7 @@ func Debug() (e *Entry) {
 
 // Info starts a new message with info level.
 func Info() (e *Entry) {
-	if DefaultLogger.silent(InfoLevel) {
+	if DefaultLogger.silent(InfoLevel) || DefaultLogger.sampleout(InfoLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(InfoLevel)
@@ -170,7 +233,7 @@ func Info() (e *Entry) {
 
 // Warn starts a new message with warning level.
 func Warn() (e *Entry) {
-	if DefaultLogger.silent(WarnLevel) {
+	if DefaultLogger.silent(WarnLevel) || DefaultLogger.sampleout(WarnLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(WarnLevel)
@@ -186,7 +249,7 @@ func Warn() (e *Entry) {
 
 // Error starts a new message with error level.
 func Error() (e *Entry) {
-	if DefaultLogger.silent(ErrorLevel) {
+	if DefaultLogger.silent(ErrorLevel) || DefaultLogger.sampleout(ErrorLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(ErrorLevel)
@@ -202,7 +265,7 @@ func Error() (e *Entry) {
 
 // Fatal starts a new message with fatal level.
 func Fatal() (e *Entry) {
-	if DefaultLogger.silent(FatalLevel) {
+	if DefaultLogger.silent(FatalLevel) || DefaultLogger.sampleout(FatalLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(FatalLevel)
@@ -218,7 +281,7 @@ func Fatal() (e *Entry) {
 
 // Panic starts a new message with panic level.
 func Panic() (e *Entry) {
-	if DefaultLogger.silent(PanicLevel) {
+	if DefaultLogger.silent(PanicLevel) || DefaultLogger.sampleout(PanicLevel) {
 		return nil
 	}
 	e = DefaultLogger.header(PanicLevel)
@@ -247,7 +310,7 @@ func Printf(format string, v ...any) {
 
 // Trace starts a new message with trace level.
 func (l *Logger) Trace() (e *Entry) {
-	if l.silent(TraceLevel) {
+	if l.silent(TraceLevel) || l.sampleout(TraceLevel) {
 		return nil
 	}
 	e = l.header(TraceLevel)
@@ -263,7 +326,7 @@ func (l *Logger) Trace() (e *Entry) {
 
 // Debug starts a new message with debug level.
 func (l *Logger) Debug() (e *Entry) {
-	if l.silent(DebugLevel) {
+	if l.silent(DebugLevel) || l.sampleout(DebugLevel) {
 		return nil
 	}
 	e = l.header(DebugLevel)
@@ -279,7 +342,7 @@ func (l *Logger) Debug() (e *Entry) {
 
 // Info starts a new message with info level.
 func (l *Logger) Info() (e *Entry) {
-	if l.silent(InfoLevel) {
+	if l.silent(InfoLevel) || l.sampleout(InfoLevel) {
 		return nil
 	}
 	e = l.header(InfoLevel)
@@ -295,7 +358,7 @@ func (l *Logger) Info() (e *Entry) {
 
 // Warn starts a new message with warning level.
 func (l *Logger) Warn() (e *Entry) {
-	if l.silent(WarnLevel) {
+	if l.silent(WarnLevel) || l.sampleout(WarnLevel) {
 		return nil
 	}
 	e = l.header(WarnLevel)
@@ -311,7 +374,7 @@ func (l *Logger) Warn() (e *Entry) {
 
 // Error starts a new message with error level.
 func (l *Logger) Error() (e *Entry) {
-	if l.silent(ErrorLevel) {
+	if l.silent(ErrorLevel) || l.sampleout(ErrorLevel) {
 		return nil
 	}
 	e = l.header(ErrorLevel)
@@ -327,7 +390,7 @@ func (l *Logger) Error() (e *Entry) {
 
 // Fatal starts a new message with fatal level.
 func (l *Logger) Fatal() (e *Entry) {
-	if l.silent(FatalLevel) {
+	if l.silent(FatalLevel) || l.sampleout(FatalLevel) {
 		return nil
 	}
 	e = l.header(FatalLevel)
@@ -343,7 +406,7 @@ func (l *Logger) Fatal() (e *Entry) {
 
 // Panic starts a new message with panic level.
 func (l *Logger) Panic() (e *Entry) {
-	if l.silent(PanicLevel) {
+	if l.silent(PanicLevel) || l.sampleout(PanicLevel) {
 		return nil
 	}
 	e = l.header(PanicLevel)
@@ -372,7 +435,7 @@ func (l *Logger) Log() (e *Entry) {
 
 // WithLevel starts a new message with level.
 func (l *Logger) WithLevel(level Level) (e *Entry) {
-	if l.silent(level) {
+	if l.silent(level) || l.sampleout(level) {
 		return nil
 	}
 	e = l.header(level)
@@ -392,7 +455,7 @@ func (l *Logger) Err(err error) (e *Entry) {
 	if err != nil {
 		level = ErrorLevel
 	}
-	if l.silent(level) {
+	if l.silent(level) || l.sampleout(level) {
 		return nil
 	}
 	e = l.header(level)
@@ -417,6 +480,11 @@ func (l *Logger) SetLevel(level Level) {
 	atomic.StoreUint32((*uint32)(&l.Level), uint32(level))
 }
 
+// loadLevel atomically loads the level of logger.
+func (l *Logger) loadLevel() Level {
+	return Level(atomic.LoadUint32((*uint32)(&l.Level)))
+}
+
 // Printf sends a log entry without extra field. Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Printf(format string, v ...any) {
 	e := l.header(noLevel)
@@ -470,17 +538,35 @@ func (l *Logger) header(level Level) *Entry {
 	} else {
 		e.w = IOWriter{os.Stderr}
 	}
+	e.hooks = l.Hooks
+	e.extractors = l.ContextExtractors
+	e.errchain = l.ErrorChain
+	e.redactor = l.Redactor
+	e.names = l.FieldNames
+	e.enc = l.Encoding
+	e.encoded = 0
+	e.errhandler = l.ErrorHandler
+	e.exit = l.Exit
+	e.sampler = nil
+	if l.Sampler != nil {
+		e.sampler, _ = l.Sampler.(MessageSampler)
+	}
 	// time
 	if l.TimeField == "" {
 		e.buf = append(e.buf, "{\""...)
-		e.buf = append(e.buf, TimeKey...)
+		e.buf = append(e.buf, l.FieldNames.time()...)
 		e.buf = append(e.buf, "\":"...)
 	} else {
 		e.buf = append(e.buf, '{', '"')
 		e.buf = append(e.buf, l.TimeField...)
 		e.buf = append(e.buf, '"', ':')
 	}
+	n := len(e.buf)
 	offset := timeOffset
+	if l.Clock != nil {
+		e.buf = appendClockTime(e.buf, l.Clock(), l.TimeFormat, l.TimeLocation)
+		goto headerlevel
+	}
 	if l.TimeLocation != nil {
 		if l.TimeLocation == time.UTC {
 			offset = 0
@@ -666,62 +752,108 @@ func (l *Logger) header(level Level) *Entry {
 	}
 headerlevel:
 	// level
-	switch level {
-	case DebugLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, DebugLevelString...)
-		e.buf = append(e.buf, '"')
-	case InfoLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, InfoLevelString...)
-		e.buf = append(e.buf, '"')
-	case WarnLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, WarnLevelString...)
-		e.buf = append(e.buf, '"')
-	case ErrorLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, ErrorLevelString...)
-		e.buf = append(e.buf, '"')
-	case TraceLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, TraceLevelString...)
-		e.buf = append(e.buf, '"')
-	case FatalLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, FatalLevelString...)
-		e.buf = append(e.buf, '"')
-	case PanicLevel:
-		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
-		e.buf = append(e.buf, "\":\""...)
-		e.buf = append(e.buf, PanicLevelString...)
-		e.buf = append(e.buf, '"')
+	if e.enc != JSONEncoding {
+		e.encodeHeader(n, level)
+	} else {
+		switch level {
+		case DebugLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, DebugLevelString...)
+			e.buf = append(e.buf, '"')
+		case InfoLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, InfoLevelString...)
+			e.buf = append(e.buf, '"')
+		case WarnLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, WarnLevelString...)
+			e.buf = append(e.buf, '"')
+		case ErrorLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, ErrorLevelString...)
+			e.buf = append(e.buf, '"')
+		case TraceLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, TraceLevelString...)
+			e.buf = append(e.buf, '"')
+		case FatalLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, FatalLevelString...)
+			e.buf = append(e.buf, '"')
+		case PanicLevel:
+			e.buf = append(e.buf, ",\""...)
+			e.buf = append(e.buf, e.names.level()...)
+			e.buf = append(e.buf, "\":\""...)
+			e.buf = append(e.buf, PanicLevelString...)
+			e.buf = append(e.buf, '"')
+		default:
+			if c := customLevel(level); c != nil {
+				e.buf = append(e.buf, ",\""...)
+				e.buf = append(e.buf, e.names.level()...)
+				e.buf = append(e.buf, "\":\""...)
+				e.buf = append(e.buf, c.Name...)
+				e.buf = append(e.buf, '"')
+			}
+		}
 	}
 	// context
 	if l.Context != nil {
 		e.buf = append(e.buf, l.Context...)
 	}
+	// stack
+	if l.StackLevel != 0 && level.rank() >= l.StackLevel.rank() && level != noLevel {
+		e.stackframes(3, l.StackOptions)
+	}
 	return e
 }
 
+// appendClockTime appends the entry time t returned by Logger.Clock to dst in timefmt.
+func appendClockTime(dst []byte, t time.Time, timefmt string, loc *time.Location) []byte {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	switch timefmt {
+	case "":
+		dst = append(dst, '"')
+		dst = t.AppendFormat(dst, "2006-01-02T15:04:05.000Z07:00")
+		dst = append(dst, '"')
+	case TimeFormatUnix:
+		dst = strconv.AppendInt(dst, t.Unix(), 10)
+	case TimeFormatUnixMs:
+		dst = strconv.AppendInt(dst, t.UnixNano()/1000000, 10)
+	case TimeFormatUnixWithMs:
+		ms := t.Nanosecond() / 1000000
+		dst = strconv.AppendInt(dst, t.Unix(), 10)
+		dst = append(dst, '.', byte('0'+ms/100), smallsString[ms%100*2], smallsString[ms%100*2+1])
+	default:
+		dst = append(dst, '"')
+		dst = t.AppendFormat(dst, timefmt)
+		dst = append(dst, '"')
+	}
+	return dst
+}
+
 // Time append append t formated as string using time.RFC3339Nano.
 func (e *Entry) Time(key string, t time.Time) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		var tmp [64]byte
+		return e.encodeBytes(key, t.AppendFormat(tmp[:0], "2006-01-02T15:04:05.999Z07:00"))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -817,6 +949,9 @@ func (e *Entry) Bool(key string, b bool) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeBool(key, b)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -849,6 +984,12 @@ func (e *Entry) Dur(key string, d time.Duration) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		if d%time.Millisecond == 0 {
+			return e.encodeInt(key, int64(d/time.Millisecond))
+		}
+		return e.encodeFloat(key, float64(d)/float64(time.Millisecond), 64)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -963,17 +1104,30 @@ func (e *Entry) AnErr(key string, err error) *Entry {
 	}
 
 	if err == nil {
+		if e.enc != JSONEncoding {
+			return e.encodeNull(key)
+		}
 		e.buf = append(e.buf, ',', '"')
 		e.buf = append(e.buf, key...)
 		e.buf = append(e.buf, "\":null"...)
 		return e
 	}
 
+	o, ok := err.(ObjectMarshaler)
+	if e.enc != JSONEncoding && !ok && !e.errchain {
+		return e.encodeStr(key, err.Error())
+	}
+
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
 	e.buf = append(e.buf, '"', ':')
-	if o, ok := err.(ObjectMarshaler); ok {
+	if ok {
+		enc := e.enc
+		e.enc = JSONEncoding
 		o.MarshalObject(e)
+		e.enc = enc
+	} else if e.errchain {
+		e.chain(err, 0)
 	} else {
 		e.buf = append(e.buf, '"')
 		e.string(err.Error())
@@ -997,6 +1151,8 @@ func (e *Entry) Errs(key string, errs []error) *Entry {
 		}
 		if err == nil {
 			e.buf = append(e.buf, "null"...)
+		} else if e.errchain {
+			e.chain(err, 0)
 		} else {
 			e.buf = append(e.buf, '"')
 			e.string(err.Error())
@@ -1042,6 +1198,9 @@ func (e *Entry) Float64(key string, f float64) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeFloat(key, f, 64)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1055,6 +1214,9 @@ func (e *Entry) Float32(key string, f float32) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeFloat(key, float64(f), 32)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1106,6 +1268,9 @@ func (e *Entry) Int64(key string, i int64) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeInt(key, i)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1119,6 +1284,9 @@ func (e *Entry) Uint(key string, i uint) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeUint(key, uint64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1132,6 +1300,9 @@ func (e *Entry) Uint64(key string, i uint64) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeUint(key, i)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1145,6 +1316,9 @@ func (e *Entry) Int(key string, i int) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeInt(key, int64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1158,6 +1332,9 @@ func (e *Entry) Int32(key string, i int32) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeInt(key, int64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1171,6 +1348,9 @@ func (e *Entry) Int16(key string, i int16) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeInt(key, int64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1184,6 +1364,9 @@ func (e *Entry) Int8(key string, i int8) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeInt(key, int64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1197,6 +1380,9 @@ func (e *Entry) Uint32(key string, i uint32) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeUint(key, uint64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1210,6 +1396,9 @@ func (e *Entry) Uint16(key string, i uint16) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeUint(key, uint64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1223,6 +1412,9 @@ func (e *Entry) Uint8(key string, i uint8) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding {
+		return e.encodeUint(key, uint64(i))
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1452,6 +1644,12 @@ func (e *Entry) Str(key string, val string) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.redactor != nil && e.redactor.match(key) {
+		return e.redacted(key, val)
+	}
+	if e.enc != JSONEncoding {
+		return e.encodeStr(key, val)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1480,6 +1678,15 @@ func (e *Entry) Stringer(key string, val fmt.Stringer) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.redactor != nil && val != nil && e.redactor.match(key) {
+		return e.redacted(key, val.String())
+	}
+	if e.enc != JSONEncoding {
+		if val == nil {
+			return e.encodeNull(key)
+		}
+		return e.encodeStr(key, val.String())
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1518,6 +1725,7 @@ func (e *Entry) Strs(key string, vals []string) *Entry {
 	if e == nil {
 		return nil
 	}
+	redact := e.redactor != nil && e.redactor.match(key)
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1527,7 +1735,11 @@ func (e *Entry) Strs(key string, vals []string) *Entry {
 			e.buf = append(e.buf, ',')
 		}
 		e.buf = append(e.buf, '"')
-		e.string(val)
+		if redact {
+			e.buf = e.redactor.appendRedacted(e.buf, val)
+		} else {
+			e.string(val)
+		}
 		e.buf = append(e.buf, '"')
 	}
 	e.buf = append(e.buf, ']')
@@ -1575,6 +1787,12 @@ func (e *Entry) Bytes(key string, val []byte) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.redactor != nil && e.redactor.match(key) {
+		return e.redacted(key, b2s(val))
+	}
+	if e.enc != JSONEncoding {
+		return e.encodeBinary(key, val)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1589,6 +1807,9 @@ func (e *Entry) BytesOrNil(key string, val []byte) *Entry {
 	if e == nil {
 		return nil
 	}
+	if e.enc != JSONEncoding && val != nil {
+		return e.encodeBinary(key, val)
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
@@ -1858,7 +2079,7 @@ func (e *Entry) Stack() *Entry {
 	}
 
 	e.buf = append(e.buf, ",\""...)
-	e.buf = append(e.buf, StackKey...)
+	e.buf = append(e.buf, e.names.stack()...)
 	e.buf = append(e.buf, "\":\""...)
 	e.bytes(stacks(false))
 	e.buf = append(e.buf, '"')
@@ -1876,6 +2097,11 @@ func (e *Entry) Discard() *Entry {
 		return e
 	}
 
+	if e.hooking {
+		e.discarded = true
+		return nil
+	}
+
 	if cap(e.buf) <= bbcap {
 		epool.Put(e)
 	}
@@ -1905,26 +2131,110 @@ var GoidKey = "goid"
 // StackKey defines the field name for the stack field.
 var StackKey = "stack"
 
+// FieldNames defines the names of builtin fields of a Logger.
+// The empty names fall back to the package-level TimeKey, LevelKey, etc.
+type FieldNames struct {
+	Time       string
+	Level      string
+	Message    string
+	Caller     string
+	CallerFunc string
+	Goid       string
+	Stack      string
+}
+
+func (n *FieldNames) time() string {
+	if n != nil && n.Time != "" {
+		return n.Time
+	}
+	return TimeKey
+}
+
+func (n *FieldNames) level() string {
+	if n != nil && n.Level != "" {
+		return n.Level
+	}
+	return LevelKey
+}
+
+func (n *FieldNames) message() string {
+	if n != nil && n.Message != "" {
+		return n.Message
+	}
+	return MessageKey
+}
+
+func (n *FieldNames) caller() string {
+	if n != nil && n.Caller != "" {
+		return n.Caller
+	}
+	return CallerKey
+}
+
+func (n *FieldNames) callerFunc() string {
+	if n != nil && n.CallerFunc != "" {
+		return n.CallerFunc
+	}
+	return CallerFuncKey
+}
+
+func (n *FieldNames) goid() string {
+	if n != nil && n.Goid != "" {
+		return n.Goid
+	}
+	return GoidKey
+}
+
+func (n *FieldNames) stack() string {
+	if n != nil && n.Stack != "" {
+		return n.Stack
+	}
+	return StackKey
+}
+
 // Msg sends the entry with msg added as the message field if not empty.
 func (e *Entry) Msg(msg string) {
 	if e == nil {
 		return
 	}
 
-	if msg != "" {
+	if e.sampler != nil && e.sampleout(msg) {
+		return
+	}
+
+	if e.hooks != nil && !e.hook(msg) {
+		return
+	}
+
+	e.msg(msg)
+}
+
+func (e *Entry) msg(msg string) {
+	if e.enc != JSONEncoding {
+		e.encodeMsg(msg)
+	} else if msg != "" {
 		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, MessageKey...)
+		e.buf = append(e.buf, e.names.message()...)
 		e.buf = append(e.buf, "\":\""...)
 		e.string(msg)
 		e.buf = append(e.buf, "\"}\n"...)
 	} else {
 		e.buf = append(e.buf, '}', '\n')
 	}
-	_, _ = e.w.WriteEntry(e)
-	if (e.Level == FatalLevel) && notTest {
-		os.Exit(255)
+	if _, err := e.w.WriteEntry(e); err != nil {
+		e.handleError(err)
+	}
+	if e.Level == FatalLevel {
+		if e.exit != nil {
+			flushOnExit()
+			e.exit(255)
+		} else if notTest {
+			flushOnExit()
+			os.Exit(255)
+		}
 	}
 	if (e.Level == PanicLevel) && notTest {
+		flushOnExit()
 		panic(msg)
 	}
 	if cap(e.buf) <= bbcap {
@@ -1953,18 +2263,32 @@ func (e *Entry) Msgf(format string, v ...any) {
 		return
 	}
 
+	if e.sampler != nil && e.sampleout(format) {
+		return
+	}
+
 	b := bbpool.Get().(*bb)
 	b.B = b.B[:0]
-	e.buf = append(e.buf, ",\""...)
-	e.buf = append(e.buf, MessageKey...)
-	e.buf = append(e.buf, "\":\""...)
 	fmt.Fprintf(b, format, v...)
-	e.bytes(b.B)
-	e.buf = append(e.buf, '"')
+	if e.hooks != nil && !e.hook(b2s(b.B)) {
+		if cap(b.B) <= bbcap {
+			bbpool.Put(b)
+		}
+		return
+	}
+	if e.enc != JSONEncoding {
+		e.encodeBytes(e.names.message(), b.B)
+	} else {
+		e.buf = append(e.buf, ",\""...)
+		e.buf = append(e.buf, e.names.message()...)
+		e.buf = append(e.buf, "\":\""...)
+		e.bytes(b.B)
+		e.buf = append(e.buf, '"')
+	}
 	if cap(b.B) <= bbcap {
 		bbpool.Put(b)
 	}
-	e.Msg("")
+	e.msg("")
 }
 
 // Msgs sends the entry with msgs added as the message field if not empty.
@@ -1975,16 +2299,32 @@ func (e *Entry) Msgs(args ...any) {
 
 	b := bbpool.Get().(*bb)
 	b.B = b.B[:0]
-	e.buf = append(e.buf, ",\""...)
-	e.buf = append(e.buf, MessageKey...)
-	e.buf = append(e.buf, "\":\""...)
 	fmt.Fprint(b, args...)
-	e.bytes(b.B)
-	e.buf = append(e.buf, '"')
+	if e.sampler != nil && e.sampleout(b2s(b.B)) {
+		if cap(b.B) <= bbcap {
+			bbpool.Put(b)
+		}
+		return
+	}
+	if e.hooks != nil && !e.hook(b2s(b.B)) {
+		if cap(b.B) <= bbcap {
+			bbpool.Put(b)
+		}
+		return
+	}
+	if e.enc != JSONEncoding {
+		e.encodeBytes(e.names.message(), b.B)
+	} else {
+		e.buf = append(e.buf, ",\""...)
+		e.buf = append(e.buf, e.names.message()...)
+		e.buf = append(e.buf, "\":\""...)
+		e.bytes(b.B)
+		e.buf = append(e.buf, '"')
+	}
 	if cap(b.B) <= bbcap {
 		bbpool.Put(b)
 	}
-	e.Msg("")
+	e.msg("")
 }
 
 func (e *Entry) caller(n int, pc uintptr, fullpath bool) {
@@ -1994,47 +2334,58 @@ func (e *Entry) caller(n int, pc uintptr, fullpath bool) {
 
 	file, line, name := pcFileLineName(pc)
 	if !fullpath {
-		var i, j, k int
-		if k = strings.IndexByte(file, '@'); k <= 0 {
-			k = len(file) - 1
-		}
-		for i = k; i >= 0; i-- {
-			if file[i] == '/' {
-				break
-			}
-		}
-		if i > 0 {
-			for j = i - 1; j >= 0; j-- {
-				if file[j] == '/' {
-					break
-				}
-			}
-			if j > 0 {
-				i = j
-			}
-			file = file[i+1:]
-		}
-		if i = strings.LastIndexByte(name, '/'); i > 0 {
-			name = name[i+1:]
-		}
+		file, name = trimFileName(file), trimFuncName(name)
 	}
 
 	e.buf = append(e.buf, ",\""...)
-	e.buf = append(e.buf, CallerKey...)
+	e.buf = append(e.buf, e.names.caller()...)
 	e.buf = append(e.buf, "\":\""...)
 	e.buf = append(e.buf, file...)
 	e.buf = append(e.buf, ':')
 	e.buf = strconv.AppendInt(e.buf, int64(line), 10)
 	e.buf = append(e.buf, "\",\""...)
-	e.buf = append(e.buf, CallerFuncKey...)
+	e.buf = append(e.buf, e.names.callerFunc()...)
 	e.buf = append(e.buf, "\":\""...)
 	e.buf = append(e.buf, name...)
 	e.buf = append(e.buf, "\",\""...)
-	e.buf = append(e.buf, GoidKey...)
+	e.buf = append(e.buf, e.names.goid()...)
 	e.buf = append(e.buf, "\":"...)
 	e.buf = strconv.AppendInt(e.buf, int64(goid()), 10)
 }
 
+// trimFileName trims file to the last two path elements, e.g. "log/logger.go".
+func trimFileName(file string) string {
+	var i, j, k int
+	if k = strings.IndexByte(file, '@'); k <= 0 {
+		k = len(file) - 1
+	}
+	for i = k; i >= 0; i-- {
+		if file[i] == '/' {
+			break
+		}
+	}
+	if i > 0 {
+		for j = i - 1; j >= 0; j-- {
+			if file[j] == '/' {
+				break
+			}
+		}
+		if j > 0 {
+			i = j
+		}
+		file = file[i+1:]
+	}
+	return file
+}
+
+// trimFuncName trims the module path of function name, e.g. "log.(*Entry).Msg".
+func trimFuncName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i > 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
 var escapes = [256]bool{
 	'"':  true,
 	'<':  true,
@@ -2184,10 +2535,14 @@ func (e *Entry) Interface(key string, i any) *Entry {
 	if o, ok := i.(ObjectMarshaler); ok {
 		return e.Object(key, o)
 	}
+	if i != nil && e.reflectAny(key, i) {
+		return e
+	}
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
 	e.buf = append(e.buf, '"', ':')
+	n := len(e.buf)
 	b := bbpool.Get().(*bb)
 	b.B = b.B[:0]
 	enc := json.NewEncoder(b)
@@ -2206,6 +2561,9 @@ func (e *Entry) Interface(key string, i any) *Entry {
 	if cap(b.B) <= bbcap {
 		bbpool.Put(b)
 	}
+	if e.redactor != nil {
+		e.buf = e.redactor.redactJSON(e.buf, n, key)
+	}
 
 	return e
 }
@@ -2225,13 +2583,19 @@ func (e *Entry) Object(key string, obj ObjectMarshaler) *Entry {
 	}
 
 	n := len(e.buf)
+	redactor, enc, redacting := e.redactor, e.enc, e.redacting
+	e.redactor, e.enc, e.redacting = nil, JSONEncoding, e.tagRedactor()
 	obj.MarshalObject(e)
+	e.redactor, e.enc, e.redacting = redactor, enc, redacting
 	if n < len(e.buf) {
 		e.buf[n] = '{'
 		e.buf = append(e.buf, '}')
 	} else {
 		e.buf = append(e.buf, "null"...)
 	}
+	if redactor != nil {
+		e.buf = redactor.redactJSON(e.buf, n, key)
+	}
 
 	return e
 }
@@ -2252,7 +2616,11 @@ func (e *Entry) Objects(key string, objects any) *Entry {
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
-	e.buf = append(e.buf, '"', ':', '[')
+	e.buf = append(e.buf, '"', ':')
+	n := len(e.buf)
+	redactor, enc, redacting := e.redactor, e.enc, e.redacting
+	e.redactor, e.enc, e.redacting = nil, JSONEncoding, e.tagRedactor()
+	e.buf = append(e.buf, '[')
 	for i := 0; i < values.Len(); i++ {
 		if i != 0 {
 			e.buf = append(e.buf, ',')
@@ -2270,6 +2638,10 @@ func (e *Entry) Objects(key string, objects any) *Entry {
 		}
 	}
 	e.buf = append(e.buf, ']')
+	e.redactor, e.enc, e.redacting = redactor, enc, redacting
+	if redactor != nil {
+		e.buf = redactor.redactJSON(e.buf, n, key)
+	}
 	return e
 }
 
@@ -2301,6 +2673,9 @@ func (e *Entry) Any(key string, value any) *Entry {
 		return nil
 	}
 
+	if e.redactor != nil && e.redactor.match(key) {
+		return e.redactedAny(key, value)
+	}
 	if value == nil || (*[2]uintptr)(unsafe.Pointer(&value))[1] == 0 {
 		e.buf = append(e.buf, ',', '"')
 		e.buf = append(e.buf, key...)
@@ -2313,7 +2688,10 @@ func (e *Entry) Any(key string, value any) *Entry {
 		e.buf = append(e.buf, ',', '"')
 		e.buf = append(e.buf, key...)
 		e.buf = append(e.buf, '"', ':')
+		enc := e.enc
+		e.enc = JSONEncoding
 		value.MarshalObject(e)
+		e.enc = enc
 	case Context:
 		e.Dict(key, value)
 	case []time.Duration:
@@ -2332,7 +2710,11 @@ func (e *Entry) Any(key string, value any) *Entry {
 		e.buf = append(e.buf, ',', '"')
 		e.buf = append(e.buf, key...)
 		e.buf = append(e.buf, '"', ':')
+		n := len(e.buf)
 		e.buf = append(e.buf, value...)
+		if e.redactor != nil {
+			e.buf = e.redactor.redactJSON(e.buf, n, key)
+		}
 	case []bool:
 		e.Bools(key, value)
 	case []byte:
@@ -2378,9 +2760,13 @@ func (e *Entry) Any(key string, value any) *Entry {
 	case fmt.Stringer:
 		e.Stringer(key, value)
 	default:
+		if e.reflectAny(key, value) {
+			break
+		}
 		e.buf = append(e.buf, ',', '"')
 		e.buf = append(e.buf, key...)
 		e.buf = append(e.buf, '"', ':')
+		n := len(e.buf)
 		b := bbpool.Get().(*bb)
 		b.B = b.B[:0]
 		enc := json.NewEncoder(b)
@@ -2399,6 +2785,9 @@ func (e *Entry) Any(key string, value any) *Entry {
 		if cap(b.B) <= bbcap {
 			bbpool.Put(b)
 		}
+		if e.redactor != nil {
+			e.buf = e.redactor.redactJSON(e.buf, n, key)
+		}
 	}
 	return e
 }
@@ -2473,11 +2862,16 @@ func (e *Entry) Dict(key string, ctx Context) *Entry {
 
 	e.buf = append(e.buf, ',', '"')
 	e.buf = append(e.buf, key...)
-	e.buf = append(e.buf, '"', ':', '{')
+	e.buf = append(e.buf, '"', ':')
+	n := len(e.buf)
+	e.buf = append(e.buf, '{')
 	if len(ctx) > 0 {
 		e.buf = append(e.buf, ctx[1:]...)
 	}
 	e.buf = append(e.buf, '}')
+	if e.redactor != nil {
+		e.buf = e.redactor.redactJSON(e.buf, n, key)
+	}
 	return e
 }
 
@@ -1,35 +1,277 @@
 package log
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
-var categorizedLoggers sync.Map // key: string, value: *Logger
+// categoryRegistry holds the categorized loggers and level rules of a parent logger.
+type categoryRegistry struct {
+	loggers sync.Map // key: string, value: *CategorizedLogger
 
+	mu    sync.Mutex
+	rules []categoryRule
+	seq   uint64 // the sequence of the level changes
+}
+
+// categoryRule sets the level of categories matched by pattern.
+type categoryRule struct {
+	pattern string
+	level   Level
+	seq     uint64
+}
+
+// match reports whether the category name is matched by the rule, an empty pattern
+// matches all categories, a name pattern matches the category and its descendants.
+func (r categoryRule) match(name string) bool {
+	switch {
+	case r.pattern == "":
+		return true
+	case strings.ContainsAny(r.pattern, "*?"):
+		return globMatch(r.pattern, name)
+	default:
+		return name == r.pattern || (strings.HasPrefix(name, r.pattern) && name[len(r.pattern)] == '.')
+	}
+}
+
+// rule returns the last rule matching name.
+func (r *categoryRegistry) rule(name string) (rule categoryRule, ok bool) {
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if r.rules[i].match(name) {
+			return r.rules[i], true
+		}
+	}
+	return
+}
+
+// resolve returns the level of category name, which is the level of the latest change
+// by a matching rule or by SetLevel of the category, or else the level of the nearest
+// categorized parent, or else the level of logger l.
+func (r *categoryRegistry) resolve(l *Logger, name string) Level {
+	var c *CategorizedLogger
+	if v, ok := r.loggers.Load(name); ok && v.(*CategorizedLogger).seq != 0 {
+		c = v.(*CategorizedLogger)
+	}
+	if rule, ok := r.rule(name); ok && (c == nil || rule.seq > c.seq) {
+		return rule.level
+	}
+	if c != nil {
+		return c.level
+	}
+	for parent := name; strings.LastIndexByte(parent, '.') > 0; {
+		parent = parent[:strings.LastIndexByte(parent, '.')]
+		if _, ok := r.loggers.Load(parent); ok {
+			return r.resolve(l, parent)
+		}
+	}
+	return l.loadLevel()
+}
+
+// refresh updates the levels of the categorized loggers after the level changes.
+func (r *categoryRegistry) refresh(l *Logger) {
+	r.loggers.Range(func(key, value any) bool {
+		value.(*CategorizedLogger).Logger.SetLevel(r.resolve(l, key.(string)))
+		return true
+	})
+}
+
+// categories returns the category registry of the logger, it is shared by the value copies of logger afterwards.
+func (l *Logger) categories() *categoryRegistry {
+	p := (*unsafe.Pointer)(unsafe.Pointer(&l.registry))
+	if registry := (*categoryRegistry)(atomic.LoadPointer(p)); registry != nil {
+		return registry
+	}
+	registry := new(categoryRegistry)
+	if atomic.CompareAndSwapPointer(p, nil, unsafe.Pointer(registry)) {
+		return registry
+	}
+	return (*categoryRegistry)(atomic.LoadPointer(p))
+}
+
+// CategorizedLogger is a Logger with a dotted hierarchical category, e.g. "db.pool".
 type CategorizedLogger struct {
 	Logger
 	Category string
+
+	parent *Logger
+	level  Level  // the level set by SetLevel
+	seq    uint64 // the sequence of SetLevel, 0 if the level is inherited
 }
 
-// Categorized returns a cloned logger for category `name`.
+// Categorized returns a cloned logger for category `name`, the categorized loggers
+// are cached by the logger and name. A dotted name such as "db.pool" inherits the
+// level of "db" unless its own level is set by SetLevel or a category level rule,
+// and follows the later level changes of "db".
 func (l *Logger) Categorized(name string) *CategorizedLogger {
+	registry := l.categories()
 	// Inherit logger with added context
-	v, ok := categorizedLoggers.Load(name)
+	v, ok := registry.loggers.Load(name)
 	if ok {
 		return v.(*CategorizedLogger)
 	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if v, ok := registry.loggers.Load(name); ok {
+		return v.(*CategorizedLogger)
+	}
+	level := registry.resolve(l, name)
 	n := &CategorizedLogger{
 		Logger{
-			Level:        l.Level,
-			Caller:       l.Caller,
-			TimeField:    l.TimeField,
-			TimeFormat:   l.TimeFormat,
-			TimeLocation: l.TimeLocation,
-			Context:      NewContext(l.Context).Str("category", name).Value(),
-			Writer:       l.Writer,
+			Level:             level,
+			Caller:            l.Caller,
+			TimeField:         l.TimeField,
+			TimeFormat:        l.TimeFormat,
+			TimeLocation:      l.TimeLocation,
+			ErrorChain:        l.ErrorChain,
+			StackLevel:        l.StackLevel,
+			StackOptions:      l.StackOptions,
+			Context:           NewContext(l.Context).Str("category", name).Value(),
+			ContextExtractors: l.ContextExtractors,
+			Sampler:           l.Sampler,
+			Hooks:             l.Hooks,
+			Redactor:          l.Redactor,
+			FieldNames:        l.FieldNames,
+			Encoding:          l.Encoding,
+			ErrorHandler:      l.ErrorHandler,
+			Exit:              l.Exit,
+			Clock:             l.Clock,
+			Writer:            l.Writer,
 		},
 		name,
+		l,
+		0,
+		0,
 	}
-	categorizedLoggers.Store(name, n)
+	registry.loggers.Store(name, n)
 	return n
 }
+
+// SetLevel sets the level of the categorized logger and the descendants inheriting
+// its level, until a later category level rule matching the category changes it.
+func (c *CategorizedLogger) SetLevel(level Level) {
+	registry := c.parent.categories()
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.seq++
+	c.level, c.seq = level, registry.seq
+	registry.refresh(c.parent)
+}
+
+// Categorized returns the child logger of category `c.Category + "." + name`.
+func (c *CategorizedLogger) Categorized(name string) *CategorizedLogger {
+	return c.parent.Categorized(c.Category + "." + name)
+}
+
+// Categories returns the sorted category names of the categorized loggers created by the logger.
+func (l *Logger) Categories() (names []string) {
+	l.categories().loggers.Range(func(key, _ any) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return
+}
+
+// SetCategoryLevel sets the level of categories matched by pattern at runtime,
+// including the categorized loggers created afterwards. A name pattern such as
+// "db" matches the category and its descendants like "db.pool", a glob pattern
+// such as "http.*" matches by wildcards. The later rules and SetLevel calls of
+// categories take precedence.
+func (l *Logger) SetCategoryLevel(pattern string, level Level) {
+	registry := l.categories()
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for i, rule := range registry.rules {
+		if rule.pattern == pattern {
+			registry.rules = append(registry.rules[:i], registry.rules[i+1:]...)
+			break
+		}
+	}
+	registry.seq++
+	registry.rules = append(registry.rules, categoryRule{pattern, level, registry.seq})
+	registry.refresh(l)
+}
+
+// SetCategoryLevels replaces the category level rules by spec, e.g. "info,db=debug,http.*=warn".
+// An item without category sets the level of the logger and the default level of categories.
+// The categories matched by none of the rules are restored to their inherited levels.
+func (l *Logger) SetCategoryLevels(spec string) error {
+	var rules []categoryRule
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var pattern, name string
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			pattern, name = strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+		} else {
+			name = item
+		}
+		level := ParseLevel(name)
+		if level == noLevel {
+			return fmt.Errorf("log: invalid level %q in category spec %q", name, spec)
+		}
+		rules = append(rules, categoryRule{pattern: pattern, level: level})
+	}
+
+	registry := l.categories()
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, rule := range rules {
+		if rule.pattern == "" {
+			l.SetLevel(rule.level)
+		}
+	}
+	registry.seq++
+	for i := range rules {
+		rules[i].seq = registry.seq
+	}
+	registry.rules = rules
+	registry.refresh(l)
+	return nil
+}
+
+// categoryRule returns the level of the category level rule of pattern.
+func (l *Logger) categoryRule(pattern string) (level Level, ok bool) {
+	registry := l.categories()
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, rule := range registry.rules {
+		if rule.pattern == pattern {
+			return rule.level, true
+		}
+	}
+	return
+}
+
+// unsetCategoryLevel removes the category level rule of pattern, and restores the
+// levels of matched categorized loggers by the remaining rules or by inheritance.
+func (l *Logger) unsetCategoryLevel(pattern string) {
+	registry := l.categories()
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for i, rule := range registry.rules {
+		if rule.pattern == pattern {
+			registry.rules = append(registry.rules[:i], registry.rules[i+1:]...)
+			break
+		}
+	}
+	registry.refresh(l)
+}
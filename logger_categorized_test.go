@@ -51,6 +51,176 @@ func TestLoggerCategorizedLogLevels(t *testing.T) {
 	}
 }
 
+func TestLoggerCategorizedHierarchy(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{Level: InfoLevel, Writer: &IOWriter{Writer: &b}}
+	other := Logger{Level: ErrorLevel, Writer: &IOWriter{Writer: &b}}
+
+	if logger.Categorized("db") == other.Categorized("db") {
+		t.Fatal("categorized loggers must be cached per parent logger")
+	}
+	if other.Categorized("db").Level != ErrorLevel {
+		t.Fatal("categorized logger must inherit the level of its parent logger")
+	}
+
+	db := logger.Categorized("db")
+	db.SetLevel(DebugLevel)
+	pool := db.Categorized("pool")
+	if pool.Category != "db.pool" || pool != logger.Categorized("db.pool") {
+		t.Fatalf("child category must be dotted: %s", pool.Category)
+	}
+	if pool.Level != DebugLevel {
+		t.Fatalf("child category must inherit the level of its parent category: %s", pool.Level)
+	}
+
+	logger.Categorized("http.server")
+	logger.Categorized("http.client")
+	if names := strings.Join(logger.Categories(), ","); names != "db,db.pool,http.client,http.server" {
+		t.Fatalf("categories must be listed: %s", names)
+	}
+
+	logger.SetCategoryLevel("http.*", WarnLevel)
+	logger.Categorized("http.proxy").Info().Msg("http proxy info")
+	logger.Categorized("http.server").Info().Msg("http server info")
+	if b.Len() != 0 {
+		t.Fatalf("glob category level must be applied: %s", b.String())
+	}
+
+	logger.SetCategoryLevel("db", ErrorLevel)
+	pool.Warn().Msg("db pool warn")
+	if b.Len() != 0 || db.Level != ErrorLevel {
+		t.Fatalf("category level must be applied to descendants: %s", b.String())
+	}
+}
+
+func TestLoggerCategorizedRegistry(t *testing.T) {
+	var b bytes.Buffer
+	logger := &Logger{Level: InfoLevel, Writer: &IOWriter{Writer: &b}}
+	db := logger.Categorized("db")
+
+	// the registry is held by the logger, and shared by the value copies afterwards
+	if logger.registry == nil {
+		t.Fatal("categorized loggers must be registered in the logger")
+	}
+	copied := *logger
+	if copied.Categorized("db") != db || len(copied.Categories()) != 1 {
+		t.Fatalf("value copy of logger must keep the categorized loggers: %v", copied.Categories())
+	}
+
+	// the child logger has its own registry with the child context
+	child := logger.With().Str("service", "api").Logger()
+	child.Categorized("db").Info().Msg("hello child")
+	if child.Categorized("db") == db || !strings.Contains(b.String(), `"service":"api","category":"db"`) {
+		t.Fatalf("child logger must not share the categorized loggers of parent: %s", b.String())
+	}
+}
+
+func TestLoggerCategorizedSpec(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{Level: TraceLevel, Writer: &IOWriter{Writer: &b}}
+	server := logger.Categorized("http.server")
+
+	if err := logger.SetCategoryLevels("info, db=debug, http.*=warn, http.server.tls=trace"); err != nil {
+		t.Fatalf("SetCategoryLevels error: %+v", err)
+	}
+
+	cases := []struct {
+		Category string
+		Level    Level
+	}{
+		{"app", InfoLevel},
+		{"db", DebugLevel},
+		{"db.pool", DebugLevel},
+		{"http.server", WarnLevel},
+		{"http.server.tls", TraceLevel},
+	}
+	for _, c := range cases {
+		if level := logger.Categorized(c.Category).Level; level != c.Level {
+			t.Errorf("category %#v level must be %s, not %s", c.Category, c.Level, level)
+		}
+	}
+	if logger.Level != InfoLevel || server.Level != WarnLevel {
+		t.Fatalf("spec must set the level of logger and existing categories: %s %s", logger.Level, server.Level)
+	}
+
+	if err := logger.SetCategoryLevels("info,db=verbose"); err == nil {
+		t.Fatal("SetCategoryLevels must return error for invalid level")
+	}
+}
+
+func TestLoggerCategorizedSpecReplace(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{Level: TraceLevel, Writer: &IOWriter{Writer: &b}}
+	db := logger.Categorized("db")
+	pool := logger.Categorized("db.pool")
+	http := logger.Categorized("http")
+
+	if err := logger.SetCategoryLevels("info,db=debug"); err != nil {
+		t.Fatalf("SetCategoryLevels error: %+v", err)
+	}
+	if err := logger.SetCategoryLevels("http=error"); err != nil {
+		t.Fatalf("SetCategoryLevels error: %+v", err)
+	}
+
+	db.Debug().Msg("db debug")
+	pool.Debug().Msg("db pool debug")
+	if b.Len() != 0 {
+		t.Fatalf("categories of removed rules must be restored to the inherited level: %s", b.String())
+	}
+	if db.Level != InfoLevel || pool.Level != InfoLevel || http.Level != ErrorLevel {
+		t.Fatalf("categories must follow the replaced spec: %s %s %s", db.Level, pool.Level, http.Level)
+	}
+
+	// the categories without matching rule inherit the level of the nearest categorized parent
+	db.SetLevel(WarnLevel)
+	if err := logger.SetCategoryLevels("db.pool=trace"); err != nil {
+		t.Fatalf("SetCategoryLevels error: %+v", err)
+	}
+	if err := logger.SetCategoryLevels(""); err != nil {
+		t.Fatalf("SetCategoryLevels error: %+v", err)
+	}
+	if pool.Level != WarnLevel || http.Level != InfoLevel {
+		t.Fatalf("categories must be restored to the inherited levels: %s %s", pool.Level, http.Level)
+	}
+}
+
+func TestLoggerCategorizedInherit(t *testing.T) {
+	logger := Logger{Level: InfoLevel}
+	db := logger.Categorized("db")
+	pool := logger.Categorized("db.pool")
+	conn := pool.Categorized("conn")
+
+	db.SetLevel(DebugLevel)
+	if pool.Level != DebugLevel || conn.Level != DebugLevel {
+		t.Fatalf("existing descendants must follow the level of parent category: %s %s", pool.Level, conn.Level)
+	}
+
+	logger.SetCategoryLevel("db.pool", ErrorLevel)
+	if pool.Level != ErrorLevel || conn.Level != ErrorLevel || db.Level != DebugLevel {
+		t.Fatalf("category level rule must be applied: %s %s %s", db.Level, pool.Level, conn.Level)
+	}
+	db.SetLevel(WarnLevel)
+	if pool.Level != ErrorLevel {
+		t.Fatalf("category level rule must take precedence over the inherited level: %s", pool.Level)
+	}
+
+	logger.unsetCategoryLevel("db.pool")
+	if pool.Level != WarnLevel || conn.Level != WarnLevel {
+		t.Fatalf("descendants must inherit the level of parent category after unset: %s %s", pool.Level, conn.Level)
+	}
+
+	// the later change takes precedence
+	pool.SetLevel(TraceLevel)
+	logger.SetCategoryLevel("db", InfoLevel)
+	if pool.Level != InfoLevel {
+		t.Fatalf("later category level rule must take precedence: %s", pool.Level)
+	}
+	logger.unsetCategoryLevel("db")
+	if pool.Level != TraceLevel || db.Level != WarnLevel {
+		t.Fatalf("own level of category must be restored after unset: %s %s", pool.Level, db.Level)
+	}
+}
+
 func BenchmarkCategorizedLogger(b *testing.B) {
 	logger := Logger{
 		TimeFormat: TimeFormatUnix,
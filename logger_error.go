@@ -0,0 +1,77 @@
+package log
+
+import (
+	"reflect"
+)
+
+// maxErrorDepth limits the depth of nested errors in a structured error chain.
+const maxErrorDepth = 16
+
+// chain appends err as a json object of its message, concrete type, stack
+// trace and the wrapped errors, e.g.
+//
+//	{"message":"read config: EOF","type":"*fmt.wrapError","cause":{"message":"EOF","type":"*errors.errorString"}}
+func (e *Entry) chain(err error, depth int) {
+	e.buf = append(e.buf, "{\"message\":\""...)
+	e.string(err.Error())
+	e.buf = append(e.buf, "\",\"type\":\""...)
+	e.buf = append(e.buf, reflect.TypeOf(err).String()...)
+	e.buf = append(e.buf, '"')
+
+	if pcs := errorCallers(err); len(pcs) != 0 {
+		e.buf = append(e.buf, ",\"stack\":"...)
+		e.frames(pcs, StackOptions{})
+	}
+
+	if depth < maxErrorDepth {
+		switch x := err.(type) {
+		case interface{ Unwrap() []error }:
+			e.buf = append(e.buf, ",\"errors\":["...)
+			for i, err := range x.Unwrap() {
+				if i != 0 {
+					e.buf = append(e.buf, ',')
+				}
+				if err == nil {
+					e.buf = append(e.buf, "null"...)
+				} else {
+					e.chain(err, depth+1)
+				}
+			}
+			e.buf = append(e.buf, ']')
+		case interface{ Unwrap() error }:
+			if cause := x.Unwrap(); cause != nil {
+				e.buf = append(e.buf, ",\"cause\":"...)
+				e.chain(cause, depth+1)
+			}
+		}
+	}
+
+	e.buf = append(e.buf, '}')
+}
+
+// errorCallers returns the program counters of stack-carrying errors, which
+// expose a `Callers() []uintptr` method or a `StackTrace()` method returning
+// a slice of uintptr-based frames, e.g. github.com/pkg/errors.
+func errorCallers(err error) []uintptr {
+	switch x := err.(type) {
+	case interface{ Callers() []uintptr }:
+		return x.Callers()
+	case interface{ StackTrace() []uintptr }:
+		return x.StackTrace()
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	typ := m.Type().Out(0)
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type testFrame uintptr
+
+type testStackTrace []testFrame
+
+type testStackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func (e *testStackError) Error() string { return e.msg }
+
+func (e *testStackError) StackTrace() testStackTrace {
+	st := make(testStackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = testFrame(pc)
+	}
+	return st
+}
+
+type testCallersError struct {
+	pcs []uintptr
+}
+
+func (e testCallersError) Error() string { return "callers error" }
+
+func (e testCallersError) Callers() []uintptr { return e.pcs }
+
+func TestLoggerErrorChain(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:      InfoLevel,
+		ErrorChain: true,
+		Writer:     &IOWriter{Writer: &b},
+	}
+
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	err := fmt.Errorf("read config: %w", errors.Join(
+		&testStackError{msg: "stack error", pcs: pcs},
+		fmt.Errorf("wrap: %w", testCallersError{pcs: pcs}),
+	))
+
+	logger.Info().Err(err).Errs("errs", []error{errors.New("plain"), nil}).Msg("hello error chain")
+
+	var got struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Cause   struct {
+				Type   string `json:"type"`
+				Errors []struct {
+					Message string `json:"message"`
+					Type    string `json:"type"`
+					Stack   []struct {
+						Func string `json:"func"`
+						File string `json:"file"`
+						Line int    `json:"line"`
+					} `json:"stack"`
+					Cause *struct {
+						Type  string            `json:"type"`
+						Stack []json.RawMessage `json:"stack"`
+					} `json:"cause"`
+				} `json:"errors"`
+			} `json:"cause"`
+		} `json:"error"`
+		Errs []json.RawMessage `json:"errs"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %+v", b.String(), err)
+	}
+
+	if got.Error.Message != err.Error() || got.Error.Type != "*fmt.wrapError" {
+		t.Fatalf("bad error chain head: %s", b.String())
+	}
+	if got.Error.Cause.Type != "*errors.joinError" || len(got.Error.Cause.Errors) != 2 {
+		t.Fatalf("bad joined errors: %s", b.String())
+	}
+	first := got.Error.Cause.Errors[0]
+	if first.Type != "*log.testStackError" || len(first.Stack) == 0 {
+		t.Fatalf("bad stack error: %s", b.String())
+	}
+	if frame := first.Stack[0]; frame.Func != "log.TestLoggerErrorChain" || !strings.HasSuffix(frame.File, "/logger_error_test.go") || frame.Line == 0 {
+		t.Fatalf("bad stack frame: %+v", frame)
+	}
+	second := got.Error.Cause.Errors[1]
+	if second.Cause == nil || second.Cause.Type != "log.testCallersError" || len(second.Cause.Stack) == 0 {
+		t.Fatalf("bad callers error: %s", b.String())
+	}
+	if len(got.Errs) != 2 || !strings.Contains(string(got.Errs[0]), `"type":"*errors.errorString"`) || string(got.Errs[1]) != "null" {
+		t.Fatalf("bad errs: %s", b.String())
+	}
+
+	b.Reset()
+	logger.ErrorChain = false
+	logger.Info().Err(err).Msg("hello error string")
+	if !strings.Contains(b.String(), `"error":"read config: stack error\nwrap: callers error"`) {
+		t.Fatalf("errors must be serialized as string without ErrorChain: %s", b.String())
+	}
+}
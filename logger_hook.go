@@ -0,0 +1,39 @@
+package log
+
+// Hook defines an interface to inspect and enrich entries of a Logger.
+type Hook interface {
+	// Run runs the hook with the entry before the message is appended.
+	// It may add fields by the Entry methods or calls e.Discard() to drop the entry.
+	Run(e *Entry, level Level, msg string)
+}
+
+// The HookFunc type is an adapter to allow the use of
+// ordinary functions as log hooks. If f is a function
+// with the appropriate signature, HookFunc(f) is a
+// [Hook] that calls f.
+type HookFunc func(e *Entry, level Level, msg string)
+
+// Run calls f(e, level, msg).
+func (f HookFunc) Run(e *Entry, level Level, msg string) {
+	f(e, level, msg)
+}
+
+// hook runs the hooks of entry, returns false if the entry is discarded.
+func (e *Entry) hook(msg string) bool {
+	e.hooking = true
+	for _, h := range e.hooks {
+		h.Run(e, e.Level, msg)
+		if e.discarded {
+			break
+		}
+	}
+	e.hooking = false
+	if e.discarded {
+		e.discarded = false
+		if cap(e.buf) <= bbcap {
+			epool.Put(e)
+		}
+		return false
+	}
+	return true
+}
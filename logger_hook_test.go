@@ -0,0 +1,87 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoggerHooks(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  DebugLevel,
+		Writer: &IOWriter{Writer: &b},
+		Hooks: []Hook{
+			HookFunc(func(e *Entry, level Level, msg string) {
+				e.Str("hook_level", level.String()).Str("hook_msg", msg)
+			}),
+			HookFunc(func(e *Entry, level Level, msg string) {
+				if msg == "secret" {
+					e.Discard()
+				}
+			}),
+		},
+	}
+
+	logger.Info().Str("foo", "bar").Msg("hello")
+	if s := b.String(); !strings.Contains(s, `"foo":"bar","hook_level":"info","hook_msg":"hello","message":"hello"}`) {
+		t.Fatalf("hook fields must be added before message: %s", s)
+	}
+
+	b.Reset()
+	logger.Warn().Msgf("hello %s", "world")
+	if s := b.String(); !strings.Contains(s, `"hook_level":"warn","hook_msg":"hello world","message":"hello world"}`) {
+		t.Fatalf("hook must see the formatted message: %s", s)
+	}
+
+	b.Reset()
+	logger.Info().Msg("secret")
+	logger.Info().Msgs("sec", "ret")
+	if b.Len() != 0 {
+		t.Fatalf("hook must discard the entry: %s", b.String())
+	}
+
+	b.Reset()
+	logger.Categorized("hook").Info().Msg("hello")
+	if s := b.String(); !strings.Contains(s, `"hook_msg":"hello"`) {
+		t.Fatalf("categorized logger must inherit hooks: %s", s)
+	}
+
+	b.Reset()
+	logger.Std("", 0).Print("hello")
+	if s := b.String(); !strings.Contains(s, `"hook_msg":"hello\n"`) {
+		t.Fatalf("std logger must inherit hooks: %s", s)
+	}
+}
+
+func BenchmarkLoggerNoHooks(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Writer:     IOWriter{io.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Msg("hello world")
+	}
+}
+
+func BenchmarkLoggerHooks(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Writer:     IOWriter{io.Discard},
+		Hooks: []Hook{HookFunc(func(e *Entry, level Level, msg string) {
+			e.Str("hook", "value")
+		})},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Msg("hello world")
+	}
+}
@@ -10,5 +10,5 @@ package log
 
 //gcassert:inline
 func (l *Logger) silent(level Level) bool {
-	return level < l.Level
+	return level.rank() < l.Level.rank()
 }
@@ -7,5 +7,5 @@ import (
 )
 
 func (l *Logger) silent(level Level) bool {
-	return uint32(level) < atomic.LoadUint32((*uint32)(&l.Level))
+	return level.rank() < Level(atomic.LoadUint32((*uint32)(&l.Level))).rank()
 }
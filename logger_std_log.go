@@ -9,10 +9,12 @@ type stdLogWriter struct {
 }
 
 func (w *stdLogWriter) Write(p []byte) (int, error) {
-	if w.Logger.silent(w.Logger.Level) {
+	// the entries of std log are written at the level of logger
+	level := w.Logger.loadLevel()
+	if w.Logger.silent(level) || w.Logger.sampleout(level) {
 		return 0, nil
 	}
-	e := w.Logger.header(w.Level)
+	e := w.Logger.header(level)
 	if caller, full := w.Logger.Caller, false; caller != 0 {
 		if caller < 0 {
 			caller, full = -caller, true
@@ -44,11 +44,17 @@ func stdSlogAttrEval(e *Entry, a slog.Attr) *Entry {
 		e.buf = append(e.buf, a.Key...)
 		e.buf = append(e.buf, '"', ':')
 		i := len(e.buf)
+		redactor := e.redactor
+		e.redactor = nil
 		for _, attr := range attrs {
 			e = stdSlogAttrEval(e, attr)
 		}
+		e.redactor = redactor
 		e.buf[i] = '{'
 		e.buf = append(e.buf, '}')
+		if redactor != nil {
+			e.buf = redactor.redactJSON(e.buf, i, a.Key)
+		}
 		return e
 	case slog.KindAny:
 		fallthrough
@@ -63,6 +69,7 @@ type stdSlogHandler struct {
 	entry    Entry
 	grouping bool
 	groups   int
+	prefix   string // dotted group path for redactor
 }
 
 func (h stdSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -70,9 +77,16 @@ func (h stdSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		return &h
 	}
 	i := len(h.entry.buf)
+	if h.prefix != "" {
+		h.entry.redactor = nil
+	}
 	for _, attr := range attrs {
 		h.entry = *stdSlogAttrEval(&h.entry, attr)
 	}
+	if h.prefix != "" {
+		h.entry.redactor = h.logger.Redactor
+		h.entry.buf = h.entry.redactor.redactFields(h.entry.buf, i, h.prefix)
+	}
 	if h.grouping {
 		h.entry.buf[i] = '{'
 	}
@@ -94,19 +108,25 @@ func (h stdSlogHandler) WithGroup(name string) slog.Handler {
 	h.entry.buf = append(h.entry.buf, '"', ':')
 	h.grouping = true
 	h.groups++
+	if h.logger.Redactor != nil {
+		if h.prefix != "" {
+			h.prefix += "."
+		}
+		h.prefix += name
+	}
 	return &h
 }
 
 func (h *stdSlogHandler) Enabled(_ context.Context, level slog.Level) bool {
 	switch level {
 	case slog.LevelDebug:
-		return h.logger.Level <= DebugLevel
+		return h.logger.Level.rank() <= DebugLevel.rank()
 	case slog.LevelInfo:
-		return h.logger.Level <= InfoLevel
+		return h.logger.Level.rank() <= InfoLevel.rank()
 	case slog.LevelWarn:
-		return h.logger.Level <= WarnLevel
+		return h.logger.Level.rank() <= WarnLevel.rank()
 	case slog.LevelError:
-		return h.logger.Level <= ErrorLevel
+		return h.logger.Level.rank() <= ErrorLevel.rank()
 	}
 	return false
 }
@@ -119,10 +139,22 @@ func (h *stdSlogHandler) header(now time.Time) *Entry {
 	} else {
 		e.w = IOWriter{os.Stderr}
 	}
+	e.hooks = h.logger.Hooks
+	e.extractors = h.logger.ContextExtractors
+	e.errchain = h.logger.ErrorChain
+	e.redactor = h.logger.Redactor
+	e.names = h.logger.FieldNames
+	e.enc = JSONEncoding
+	e.errhandler = h.logger.ErrorHandler
+	e.exit = nil
+	e.sampler = nil
+	if h.logger.Sampler != nil {
+		e.sampler, _ = h.logger.Sampler.(MessageSampler)
+	}
 	// time
 	if h.logger.TimeField == "" {
 		e.buf = append(e.buf, "{\""...)
-		e.buf = append(e.buf, TimeKey...)
+		e.buf = append(e.buf, h.logger.FieldNames.time()...)
 		e.buf = append(e.buf, "\":"...)
 	} else {
 		e.buf = append(e.buf, '{', '"')
@@ -299,36 +331,40 @@ func (h *stdSlogHandler) header(now time.Time) *Entry {
 	return e
 }
 
-func (h *stdSlogHandler) Handle(_ context.Context, r slog.Record) error {
-	e := h.header(r.Time)
+func (h *stdSlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	now := r.Time
+	if h.logger.Clock != nil {
+		now = h.logger.Clock()
+	}
+	e := h.header(now)
 
 	// level
 	switch r.Level {
 	case slog.LevelDebug:
 		e.Level = DebugLevel
 		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
+		e.buf = append(e.buf, e.names.level()...)
 		e.buf = append(e.buf, "\":\""...)
 		e.buf = append(e.buf, DebugLevelString...)
 		e.buf = append(e.buf, '"')
 	case slog.LevelInfo:
 		e.Level = InfoLevel
 		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
+		e.buf = append(e.buf, e.names.level()...)
 		e.buf = append(e.buf, "\":\""...)
 		e.buf = append(e.buf, InfoLevelString...)
 		e.buf = append(e.buf, '"')
 	case slog.LevelWarn:
 		e.Level = WarnLevel
 		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
+		e.buf = append(e.buf, e.names.level()...)
 		e.buf = append(e.buf, "\":\""...)
 		e.buf = append(e.buf, WarnLevelString...)
 		e.buf = append(e.buf, '"')
 	case slog.LevelError:
 		e.Level = ErrorLevel
 		e.buf = append(e.buf, ",\""...)
-		e.buf = append(e.buf, LevelKey...)
+		e.buf = append(e.buf, e.names.level()...)
 		e.buf = append(e.buf, "\":\""...)
 		e.buf = append(e.buf, ErrorLevelString...)
 		e.buf = append(e.buf, '"')
@@ -336,6 +372,14 @@ func (h *stdSlogHandler) Handle(_ context.Context, r slog.Record) error {
 		e.Level = noLevel
 	}
 
+	if h.logger.sampleout(e.Level) {
+		e.Discard()
+		return nil
+	}
+	if e.sampler != nil && e.sampleout(r.Message) {
+		return nil
+	}
+
 	if caller := h.logger.Caller; caller != 0 && r.PC != 0 {
 		e.caller(1, r.PC, caller < 0)
 	}
@@ -344,9 +388,13 @@ func (h *stdSlogHandler) Handle(_ context.Context, r slog.Record) error {
 	if h.logger.Context != nil {
 		e.buf = append(e.buf, h.logger.Context...)
 	}
+	if h.logger.StackLevel != 0 && e.Level.rank() >= h.logger.StackLevel.rank() && e.Level != noLevel {
+		e.stackframes(2, h.logger.StackOptions)
+	}
+	e = e.Ctx(ctx)
 
 	// msg
-	e = e.Str(MessageKey, r.Message)
+	e = e.Str(e.names.message(), r.Message)
 
 	// with
 	if b := h.entry.buf; len(b) != 0 {
@@ -355,10 +403,17 @@ func (h *stdSlogHandler) Handle(_ context.Context, r slog.Record) error {
 	i := len(e.buf)
 
 	// attrs
+	if h.prefix != "" {
+		e.redactor = nil
+	}
 	r.Attrs(func(attr slog.Attr) bool {
 		e = stdSlogAttrEval(e, attr)
 		return true
 	})
+	if h.prefix != "" {
+		e.redactor = h.logger.Redactor
+		e.buf = e.redactor.redactFields(e.buf, i, h.prefix)
+	}
 
 	lastindex := func(buf []byte) int {
 		for i := len(buf) - 3; i >= 1; i-- {
@@ -405,11 +460,17 @@ func (h *stdSlogHandler) Handle(_ context.Context, r slog.Record) error {
 		}
 	}
 
-	e.Msg("")
+	if e.hooks != nil && !e.hook(r.Message) {
+		return nil
+	}
+
+	// transcode the fields to the encoding of logger
+	e.enc, e.encoded = h.logger.Encoding, 0
+	e.msg("")
 	return nil
 }
 
 // Slog wraps the Logger to provide *slog.Logger
 func (l *Logger) Slog() *slog.Logger {
-	return slog.New(&stdSlogHandler{logger: *l})
+	return slog.New(&stdSlogHandler{logger: *l, entry: Entry{redactor: l.Redactor}})
 }
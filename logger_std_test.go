@@ -3,10 +3,13 @@
 package log
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	stdLog "log"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -87,3 +90,123 @@ func TestStdSlogGroup(t *testing.T) {
 	logger1.Info("hello from group slog 1")
 	logger1.Info("hello from group slog 2")
 }
+
+func TestStdSlogHooks(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+		Hooks: []Hook{HookFunc(func(e *Entry, level Level, msg string) {
+			if msg == "drop" {
+				e.Discard()
+				return
+			}
+			e.Str("hook_msg", msg)
+		})},
+	}).Slog()
+
+	logger.WithGroup("g").Info("hello from slog hooks", "foo", "bar")
+	if s := b.String(); !strings.HasSuffix(s, `"g":{"foo":"bar"},"hook_msg":"hello from slog hooks"}`+"\n") {
+		t.Fatalf("slog hooks must append fields after groups: %s", s)
+	}
+
+	b.Reset()
+	logger.Info("drop")
+	if b.Len() != 0 {
+		t.Fatalf("slog hooks must discard the entry: %s", b.String())
+	}
+}
+
+func TestStdSlogContext(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+	}).Slog()
+
+	ctx := WithFields(context.Background(), NewContext(nil).Str("request_id", "abc").Value())
+	logger.InfoContext(ctx, "hello from slog context")
+	if s := b.String(); !strings.Contains(s, `"request_id":"abc","message":"hello from slog context"`) {
+		t.Fatalf("slog handler must add context fields: %s", s)
+	}
+}
+
+func TestStdSlogStackLevel(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:      InfoLevel,
+		StackLevel: ErrorLevel,
+		Writer:     &IOWriter{Writer: &b},
+	}).Slog()
+
+	logger.Error("hello from slog stack")
+	if s := b.String(); !strings.Contains(s, `"stack":[{"func":"log.TestStdSlogStackLevel"`) {
+		t.Fatalf("slog handler must add stack frames from the caller: %s", s)
+	}
+}
+
+func TestStdSlogRedactor(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"password", "req.authorization"}},
+	}).Slog()
+
+	logger.With("password", "123456").Info("hello", slog.Group("req", "authorization", "Bearer xyz", "path", "/"))
+	if s := b.String(); !strings.Contains(s, `"password":"[REDACTED]","req":{"authorization":"[REDACTED]","path":"/"}`) {
+		t.Fatalf("slog handler must redact attrs: %s", s)
+	}
+
+	b.Reset()
+	logger.WithGroup("req").With("authorization", "Bearer xyz").Info("hello", "password", "123456")
+	if s := b.String(); !strings.Contains(s, `"req":{"authorization":"[REDACTED]","password":"[REDACTED]"}`) {
+		t.Fatalf("slog handler must redact attrs within groups: %s", s)
+	}
+}
+
+func TestStdSlogFieldNames(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:      InfoLevel,
+		FieldNames: &FieldNames{Time: "ts", Level: "severity", Message: "msg"},
+		Writer:     &IOWriter{Writer: &b},
+	}).Slog()
+
+	logger.Warn("hello slog field names", "foo", "bar")
+	if s := b.String(); !strings.HasPrefix(s, `{"ts":`) || !strings.Contains(s, `"severity":"warn","msg":"hello slog field names","foo":"bar"}`) {
+		t.Fatalf("slog handler must honour field names: %s", s)
+	}
+}
+
+func TestStdSlogCBOREncoding(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:    InfoLevel,
+		Encoding: CBOREncoding,
+		Writer:   &IOWriter{Writer: &b},
+	}).Slog()
+
+	logger.With("a", 1).WithGroup("g").Info("hello slog cbor", "foo", "bar")
+	line, n, err := CBORToJSON(nil, b.Bytes())
+	if err != nil || n != b.Len() {
+		t.Fatalf("CBORToJSON error: %+v, %d != %d", err, n, b.Len())
+	}
+	if s := string(line); !strings.Contains(s, `"level":"info","message":"hello slog cbor","a":1,"g":{"foo":"bar"}}`) {
+		t.Fatalf("slog handler must write cbor: %s", s)
+	}
+}
+
+func TestStdSlogLogfmtEncoding(t *testing.T) {
+	var b bytes.Buffer
+	var logger *slog.Logger = (&Logger{
+		Level:    InfoLevel,
+		Encoding: LogfmtEncoding,
+		Writer:   &IOWriter{Writer: &b},
+	}).Slog()
+
+	logger.With("a", 1).WithGroup("g").Info("hello slog logfmt", "foo", "bar")
+	if s := b.String(); !strings.HasSuffix(s, ` level=info message="hello slog logfmt" a=1 g.foo=bar`+"\n") {
+		t.Fatalf("slog handler must write logfmt: %s", s)
+	}
+}
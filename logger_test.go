@@ -3,6 +3,7 @@ package log
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -398,6 +399,46 @@ func TestLoggerTimeField(t *testing.T) {
 	logger.Printf("this is no level and _time field log")
 }
 
+func TestLoggerFieldNames(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:      InfoLevel,
+		Caller:     1,
+		FieldNames: &FieldNames{Time: "ts", Level: "severity", Message: "msg", Caller: "src", CallerFunc: "fn", Goid: "gid", Stack: "trace"},
+		Writer:     &IOWriter{Writer: &b},
+	}
+
+	logger.Warn().Stack().Msg("hello field names")
+	var m map[string]any
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %+v", b.Bytes(), err)
+	}
+	for _, key := range []string{"ts", "severity", "msg", "src", "fn", "gid", "trace"} {
+		if _, ok := m[key]; !ok {
+			t.Fatalf("field %#v must be present: %s", key, b.String())
+		}
+	}
+	for _, key := range []string{TimeKey, LevelKey, MessageKey, CallerKey, CallerFuncKey, GoidKey, StackKey} {
+		if _, ok := m[key]; ok {
+			t.Fatalf("field %#v must be renamed: %s", key, b.String())
+		}
+	}
+
+	b.Reset()
+	DefaultLogger.Info().Msg("hello default field names")
+	logger.Info().Msg("hello field names")
+	if s := b.String(); !strings.Contains(s, `"severity":"info"`) || !strings.Contains(s, `"msg":"hello field names"`) {
+		t.Fatalf("field names must not affect the other loggers: %s", s)
+	}
+
+	b.Reset()
+	logger.Writer = &ConsoleWriter{Writer: &b}
+	logger.Error().Str("foo", "bar").Msg("hello console field names")
+	if s := b.String(); !strings.Contains(s, "ERR ") || !strings.Contains(s, "> hello console field names foo=bar") {
+		t.Fatalf("console writer must honour field names: %s", s)
+	}
+}
+
 func TestLoggerTimeFormat(t *testing.T) {
 	logger := Logger{}
 
@@ -437,6 +478,38 @@ func TestLoggerTimeLocation(t *testing.T) {
 	}
 }
 
+func TestLoggerClock(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Clock: func() time.Time {
+			return time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+		},
+		Writer: &IOWriter{Writer: &b},
+	}
+
+	cases := []struct {
+		TimeFormat   string
+		TimeLocation *time.Location
+		Time         string
+	}{
+		{"", nil, `"2020-01-02T03:04:05.006Z"`},
+		{"", time.FixedZone("UTC+8", 8*3600), `"2020-01-02T11:04:05.006+08:00"`},
+		{TimeFormatUnix, nil, `1577934245`},
+		{TimeFormatUnixMs, nil, `1577934245006`},
+		{TimeFormatUnixWithMs, nil, `1577934245.006`},
+		{time.RFC822, time.UTC, `"02 Jan 20 03:04 UTC"`},
+	}
+
+	for _, c := range cases {
+		b.Reset()
+		logger.TimeFormat, logger.TimeLocation = c.TimeFormat, c.TimeLocation
+		logger.Info().Msg("hello")
+		if want := `{"time":` + c.Time + `,"level":"info","message":"hello"}` + "\n"; b.String() != want {
+			t.Errorf("clock time format %q want %s got %s", c.TimeFormat, want, b.String())
+		}
+	}
+}
+
 func TestLoggerTimeOffset(t *testing.T) {
 	logger := Logger{}
 
@@ -0,0 +1,96 @@
+package log
+
+// With starts a contextual entry which extends the context of logger, the fields
+// added to it are appended to the parent context by the Logger method. e.g.
+//
+//	sublogger := logger.With().Str("service", "api").Int("shard", 2).Logger()
+func (l *Logger) With() (e *Entry) {
+	e = new(Entry)
+	e.redactor = l.Redactor
+	e.parent = l
+	return
+}
+
+// Dedup makes the Logger method remove the fields of parent context which keys
+// are overridden by the contextual entry, instead of keeping both of them.
+func (e *Entry) Dedup() *Entry {
+	if e == nil {
+		return nil
+	}
+	e.dedup = true
+	return e
+}
+
+// Logger returns a copy of the parent logger of contextual entry started by With,
+// the copy shares the writer and level of parent and its context is the parent
+// context followed by the fields of entry. For the entries not started by With,
+// e.g. the log entries of level methods, it returns a copy of DefaultLogger with
+// the context unchanged and leaves the entry untouched.
+func (e *Entry) Logger() *Logger {
+	parent := &DefaultLogger
+	if e != nil && e.parent != nil {
+		parent = e.parent
+	}
+	l := *parent
+	l.Level = parent.loadLevel()
+	// the categorized loggers of parent do not have the context of child
+	l.registry = nil
+	if e == nil || e.parent == nil || len(e.buf) == 0 {
+		l.Context = append(Context(nil), parent.Context...)
+		return &l
+	}
+	ctx := make(Context, 0, len(parent.Context)+len(e.buf))
+	if e.dedup {
+		ctx = appendContextWithout(ctx, parent.Context, e.buf)
+	} else {
+		ctx = append(ctx, parent.Context...)
+	}
+	l.Context = append(ctx, e.buf...)
+	return &l
+}
+
+// appendContextWithout appends the fields of ctx to dst except the fields which
+// keys are present in fields.
+func appendContextWithout(dst []byte, ctx, fields []byte) []byte {
+	for len(ctx) != 0 {
+		key, n := contextField(ctx)
+		if n == 0 {
+			return append(dst, ctx...)
+		}
+		if !contextHasKey(fields, key) {
+			dst = append(dst, ctx[:n]...)
+		}
+		ctx = ctx[n:]
+	}
+	return dst
+}
+
+// contextHasKey reports whether the contextual fields has a field of key.
+func contextHasKey(fields []byte, key []byte) bool {
+	for len(fields) != 0 {
+		k, n := contextField(fields)
+		if n == 0 {
+			return false
+		}
+		if string(k) == string(key) {
+			return true
+		}
+		fields = fields[n:]
+	}
+	return false
+}
+
+// contextField parses the first field of contextual fields, i.e. `,"key":value`,
+// it returns the quoted key and the length of field, or zero length if malformed.
+func contextField(b []byte) (key []byte, n int) {
+	if len(b) < 2 || b[0] != ',' || b[1] != '"' {
+		return nil, 0
+	}
+	i := jsonValueLen(b[1:])
+	if i < 2 || 1+i >= len(b) || b[1+i] != ':' {
+		return nil, 0
+	}
+	key, n = b[1:1+i], 1+i+1
+	n += jsonValueLen(b[n:])
+	return key, n
+}
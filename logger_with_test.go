@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWith(t *testing.T) {
+	var b bytes.Buffer
+	parent := Logger{
+		Level:      InfoLevel,
+		TimeField:  "time",
+		TimeFormat: TimeFormatUnix,
+		Context:    NewContext(nil).Str("app", "demo").Int("shard", 1).Value(),
+		Writer:     &IOWriter{Writer: &b},
+	}
+
+	child := parent.With().Str("service", "api").Int("shard", 2).Logger()
+	if string(parent.Context) != `,"app":"demo","shard":1` {
+		t.Fatalf("parent context must not be changed: %s", parent.Context)
+	}
+	if string(child.Context) != `,"app":"demo","shard":1,"service":"api","shard":2` {
+		t.Fatalf("child context must extend parent context: %s", child.Context)
+	}
+	if child.Writer != parent.Writer || child.Level != parent.Level {
+		t.Fatalf("child logger must share writer and level of parent")
+	}
+
+	grandchild := child.With().Bool("debug", true).Logger()
+	grandchild.Info().Msg("hello")
+	if s := b.String(); !strings.Contains(s, `"app":"demo","shard":1,"service":"api","shard":2,"debug":true,"message":"hello"}`) {
+		t.Fatalf("grandchild logger must extend child context: %s", s)
+	}
+
+	b.Reset()
+	child.Debug().Msg("not logged")
+	if b.Len() != 0 {
+		t.Fatalf("child logger must inherit level of parent: %s", b.String())
+	}
+}
+
+func TestLoggerWithDedup(t *testing.T) {
+	parent := Logger{
+		Context: NewContext(nil).Str("app", "demo").Int("shard", 1).Dict("obj", NewContext(nil).Str("a", "b,c").Value()).Str("x", "y").Value(),
+	}
+
+	cases := []struct {
+		Logger  *Logger
+		Context string
+	}{
+		{parent.With().Dedup().Int("shard", 2).Logger(), `,"app":"demo","obj":{"a":"b,c"},"x":"y","shard":2`},
+		{parent.With().Dedup().Str("obj", "flat").Str("app", "new").Logger(), `,"shard":1,"x":"y","obj":"flat","app":"new"`},
+		{parent.With().Dedup().Str("other", "z").Logger(), `,"app":"demo","shard":1,"obj":{"a":"b,c"},"x":"y","other":"z"`},
+		{parent.With().Dedup().Logger(), `,"app":"demo","shard":1,"obj":{"a":"b,c"},"x":"y"`},
+	}
+
+	for _, c := range cases {
+		if got := string(c.Logger.Context); got != c.Context {
+			t.Errorf("dedup context want %s, got %s", c.Context, got)
+		}
+	}
+}
+
+func TestLoggerWithRedactor(t *testing.T) {
+	parent := Logger{
+		Redactor: &Redactor{Keys: []string{"password"}},
+	}
+
+	child := parent.With().Str("user", "bob").Str("password", "secret").Logger()
+	if got, want := string(child.Context), `,"user":"bob","password":"[REDACTED]"`; got != want {
+		t.Fatalf("child context must be redacted, want %s, got %s", want, got)
+	}
+}
+
+func TestEntryLoggerDefault(t *testing.T) {
+	l := NewContext(nil).Str("foo", "bar").Logger()
+	if l.Writer != DefaultLogger.Writer {
+		t.Fatalf("logger of context entry must be derived from DefaultLogger")
+	}
+	if got, want := string(l.Context), string(DefaultLogger.Context); got != want {
+		t.Fatalf("logger of context entry must keep the context of DefaultLogger: %s", got)
+	}
+
+	// the log entry is not a context, and it is left untouched
+	var b bytes.Buffer
+	logger := Logger{Level: InfoLevel, Writer: &IOWriter{Writer: &b}}
+	e := logger.Info().Str("k", "v")
+	if l := e.Logger(); string(l.Context) != string(DefaultLogger.Context) {
+		t.Fatalf("logger of log entry must keep the context of DefaultLogger: %s", l.Context)
+	}
+	e.Msg("hello")
+	if s := b.String(); !strings.HasPrefix(s, `{"time":`) || !strings.HasSuffix(s, `"level":"info","k":"v","message":"hello"}`+"\n") {
+		t.Fatalf("log entry must be usable after Logger: %s", s)
+	}
+
+	var nilEntry *Entry
+	if l := nilEntry.Dedup().Logger(); l == nil || string(l.Context) != string(DefaultLogger.Context) {
+		t.Fatalf("logger of nil entry must be a copy of DefaultLogger")
+	}
+}
+
+func BenchmarkLoggerWith(b *testing.B) {
+	parent := Logger{
+		Context: NewContext(nil).Str("app", "demo").Int("shard", 1).Value(),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parent.With().Dedup().Str("service", "api").Int("shard", 2).Logger()
+	}
+}
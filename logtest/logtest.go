@@ -0,0 +1,339 @@
+// Package logtest provides a Writer which records log entries in memory,
+// the helpers to query and assert them, and a fixed clock for stable output in tests.
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+// Entry is a log entry recorded by Writer.
+type Entry struct {
+	// Level is the level of entry.
+	Level log.Level
+
+	// Message is the message field of entry.
+	Message string
+
+	// Fields are the decoded fields of entry except the time, level and message fields.
+	// The numbers are decoded as json.Number, and the nested objects as map[string]any.
+	Fields map[string]any
+
+	// Raw is the bytes of entry written by logger, without the trailing newline.
+	Raw []byte
+}
+
+// Field returns the value of field key, a dotted key such as "user.name" looks up the nested objects.
+func (e Entry) Field(key string) (any, bool) {
+	if v, ok := e.Fields[key]; ok {
+		return v, true
+	}
+	var v any = e.Fields
+	for key != "" {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, rest, _ := strings.Cut(key, ".")
+		if v, ok = m[name]; !ok {
+			return nil, false
+		}
+		key = rest
+	}
+	return v, true
+}
+
+// HasField reports whether the entry has field key of value, the value is compared
+// with the field after a JSON round trip, so log.Int("n", 1) matches value 1 or int64(1).
+func (e Entry) HasField(key string, value any) bool {
+	v, ok := e.Field(key)
+	if !ok {
+		return false
+	}
+	want, err := normalize(value)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(v, want)
+}
+
+// String returns the raw entry.
+func (e Entry) String() string {
+	return string(e.Raw)
+}
+
+// Entries is a list of recorded entries.
+type Entries []Entry
+
+// Filter returns the entries which fn returns true.
+func (es Entries) Filter(fn func(Entry) bool) Entries {
+	var result Entries
+	for _, e := range es {
+		if fn(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// FilterLevel returns the entries of level.
+func (es Entries) FilterLevel(level log.Level) Entries {
+	return es.Filter(func(e Entry) bool {
+		return e.Level == level
+	})
+}
+
+// FilterMessage returns the entries of message msg.
+func (es Entries) FilterMessage(msg string) Entries {
+	return es.Filter(func(e Entry) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterMessageContains returns the entries which message contains substr.
+func (es Entries) FilterMessageContains(substr string) Entries {
+	return es.Filter(func(e Entry) bool {
+		return strings.Contains(e.Message, substr)
+	})
+}
+
+// FilterField returns the entries which have field key of value, see Entry.HasField.
+func (es Entries) FilterField(key string, value any) Entries {
+	return es.Filter(func(e Entry) bool {
+		return e.HasField(key, value)
+	})
+}
+
+// Messages returns the messages of entries.
+func (es Entries) Messages() []string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Message
+	}
+	return msgs
+}
+
+// String returns the raw entries, one per line.
+func (es Entries) String() string {
+	var b strings.Builder
+	for _, e := range es {
+		b.WriteString("\t")
+		b.Write(e.Raw)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Writer is a log.Writer which records the JSON entries in memory, it is safe for concurrent use.
+type Writer struct {
+	// FieldNames specifies the names of builtin fields used by logger.
+	// The empty names fall back to log.TimeKey, log.LevelKey and log.MessageKey.
+	FieldNames *log.FieldNames
+
+	mu      sync.Mutex
+	entries Entries
+}
+
+// WriteEntry implements log.Writer.
+func (w *Writer) WriteEntry(e *log.Entry) (int, error) {
+	raw := bytes.TrimSuffix([]byte(e.Value()), []byte{'\n'})
+	entry := Entry{
+		Level: e.Level,
+		Raw:   append([]byte(nil), raw...),
+	}
+
+	d := json.NewDecoder(bytes.NewReader(entry.Raw))
+	d.UseNumber()
+	if err := d.Decode(&entry.Fields); err != nil {
+		return 0, err
+	}
+	timeKey, levelKey, messageKey := log.TimeKey, log.LevelKey, log.MessageKey
+	if w.FieldNames != nil {
+		if w.FieldNames.Time != "" {
+			timeKey = w.FieldNames.Time
+		}
+		if w.FieldNames.Level != "" {
+			levelKey = w.FieldNames.Level
+		}
+		if w.FieldNames.Message != "" {
+			messageKey = w.FieldNames.Message
+		}
+	}
+	entry.Message, _ = entry.Fields[messageKey].(string)
+	delete(entry.Fields, timeKey)
+	delete(entry.Fields, levelKey)
+	delete(entry.Fields, messageKey)
+
+	w.mu.Lock()
+	w.entries = append(w.entries, entry)
+	w.mu.Unlock()
+
+	return len(raw) + 1, nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (w *Writer) Entries() Entries {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append(Entries(nil), w.entries...)
+}
+
+// TakeAll returns the recorded entries and resets the writer.
+func (w *Writer) TakeAll() Entries {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entries := w.entries
+	w.entries = nil
+	return entries
+}
+
+// Len returns the number of recorded entries.
+func (w *Writer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.entries)
+}
+
+// Reset discards the recorded entries.
+func (w *Writer) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries = nil
+}
+
+// AssertLogged reports an error to tb unless an entry of level and message msg with
+// the fields of keyvals was recorded. An empty msg matches any message.
+//
+//	w.AssertLogged(t, log.ErrorLevel, "", "user.id", 42, "retry", true)
+func (w *Writer) AssertLogged(tb testing.TB, level log.Level, msg string, keyvals ...any) bool {
+	tb.Helper()
+	if len(w.match(level, msg, keyvals)) != 0 {
+		return true
+	}
+	tb.Errorf("logtest: no %s entry %s was logged, got:\n%s", level, describe(msg, keyvals), w.Entries())
+	return false
+}
+
+// AssertNotLogged reports an error to tb if an entry of level and message msg with
+// the fields of keyvals was recorded. An empty msg matches any message.
+func (w *Writer) AssertNotLogged(tb testing.TB, level log.Level, msg string, keyvals ...any) bool {
+	tb.Helper()
+	entries := w.match(level, msg, keyvals)
+	if len(entries) == 0 {
+		return true
+	}
+	tb.Errorf("logtest: unexpected %s entry %s was logged:\n%s", level, describe(msg, keyvals), entries)
+	return false
+}
+
+func (w *Writer) match(level log.Level, msg string, keyvals []any) Entries {
+	entries := w.Entries().FilterLevel(level)
+	if msg != "" {
+		entries = entries.FilterMessage(msg)
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		entries = entries.FilterField(fmt.Sprint(keyvals[i]), keyvals[i+1])
+	}
+	return entries
+}
+
+func describe(msg string, keyvals []any) string {
+	var b strings.Builder
+	if msg != "" {
+		fmt.Fprintf(&b, "%q", msg)
+	} else {
+		b.WriteString("with any message")
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], keyvals[i+1])
+	}
+	return b.String()
+}
+
+// normalize returns value after a JSON round trip, as the fields decoded by Writer.
+func normalize(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v any
+	err = d.Decode(&v)
+	return v, err
+}
+
+// TestingWriter is a log.Writer which routes the entries to the log of a test or benchmark.
+type TestingWriter struct {
+	// TB specifies the test or benchmark of output.
+	TB testing.TB
+}
+
+// NewTestingWriter returns a TestingWriter of tb.
+func NewTestingWriter(tb testing.TB) *TestingWriter {
+	return &TestingWriter{TB: tb}
+}
+
+// WriteEntry implements log.Writer.
+func (w *TestingWriter) WriteEntry(e *log.Entry) (int, error) {
+	w.TB.Helper()
+	b := e.Value()
+	w.TB.Log(string(bytes.TrimSuffix(b, []byte{'\n'})))
+	return len(b), nil
+}
+
+// FixedTime is the time of clocks returned by NewClock.
+var FixedTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// Clock is a manual clock for the Clock field of log.Logger, its time only changes by Set and Add.
+type Clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewClock returns a Clock of FixedTime.
+func NewClock() *Clock {
+	return &Clock{t: FixedTime}
+}
+
+// Now returns the time of clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set sets the time of clock to t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Add advances the time of clock by d.
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// NewLogger returns a logger of all levels which writes to w with a Clock of FixedTime,
+// so its output is stable for golden files.
+func NewLogger(w log.Writer) *log.Logger {
+	return &log.Logger{
+		Level:  log.TraceLevel,
+		Clock:  NewClock().Now,
+		Writer: w,
+	}
+}
+
+var _ log.Writer = (*Writer)(nil)
+var _ log.Writer = (*TestingWriter)(nil)
@@ -0,0 +1,187 @@
+package logtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+func TestWriter(t *testing.T) {
+	var w Writer
+	logger := NewLogger(&w)
+	logger.Context = log.NewContext(nil).Str("app", "demo").Value()
+
+	logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello")
+	logger.Error().Err(errors.New("boom")).Dict("user", log.NewContext(nil).Int("id", 7).Value()).Msg("failed")
+	logger.Debug().Bool("ok", true).Msgf("count %d", 3)
+
+	entries := w.Entries()
+	if len(entries) != 3 || w.Len() != 3 {
+		t.Fatalf("writer must record 3 entries, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Level != log.InfoLevel || e.Message != "hello" {
+		t.Errorf("wrong level or message of entry: %+v", e)
+	}
+	if _, ok := e.Fields["time"]; ok {
+		t.Errorf("fields must not contain time: %v", e.Fields)
+	}
+	if !e.HasField("foo", "bar") || !e.HasField("n", 42) || !e.HasField("n", int64(42)) || !e.HasField("app", "demo") {
+		t.Errorf("entry must have fields foo, n and app: %v", e.Fields)
+	}
+	if e.HasField("n", "42") || e.HasField("missing", nil) {
+		t.Errorf("entry must not match wrong field types or missing fields: %v", e.Fields)
+	}
+	if want := `{"time":"2020-01-02T03:04:05.000Z","level":"info","app":"demo","foo":"bar","n":42,"message":"hello"}`; e.String() != want {
+		t.Errorf("raw entry must be stable, want %s got %s", want, e.Raw)
+	}
+	if v, ok := entries[1].Field("user.id"); !ok || v != json.Number("7") {
+		t.Errorf("entry must have nested field user.id: %v", entries[1].Fields)
+	}
+
+	if got := entries.FilterLevel(log.ErrorLevel).Messages(); len(got) != 1 || got[0] != "failed" {
+		t.Errorf("wrong FilterLevel result: %v", got)
+	}
+	if got := entries.FilterMessageContains("count").FilterField("ok", true); len(got) != 1 {
+		t.Errorf("wrong FilterMessageContains and FilterField result: %v", got)
+	}
+	if got := entries.FilterMessage("hello").FilterField("foo", "baz"); len(got) != 0 {
+		t.Errorf("wrong FilterMessage and FilterField result: %v", got)
+	}
+
+	if got := w.TakeAll(); len(got) != 3 || w.Len() != 0 {
+		t.Errorf("TakeAll must return and reset the entries")
+	}
+}
+
+func TestWriterFieldNames(t *testing.T) {
+	names := &log.FieldNames{Time: "ts", Level: "lvl", Message: "msg"}
+	w := &Writer{FieldNames: names}
+	logger := NewLogger(w)
+	logger.FieldNames = names
+
+	logger.Warn().Str("k", "v").Msg("renamed")
+	e := w.Entries()[0]
+	if e.Message != "renamed" || len(e.Fields) != 1 || !e.HasField("k", "v") {
+		t.Errorf("writer must respect field names: %+v", e)
+	}
+}
+
+func TestWriterInvalid(t *testing.T) {
+	var w Writer
+	logger := NewLogger(&w)
+	logger.Encoding = log.LogfmtEncoding
+	logger.ErrorHandler = log.ErrorHandlerFunc(func(e *log.Entry, err error) {})
+
+	logger.Info().Msg("not json")
+	if w.Len() != 0 {
+		t.Errorf("writer must not record the entries which are not json")
+	}
+}
+
+type testTB struct {
+	testing.TB
+	errors []string
+	logs   []string
+}
+
+func (t *testTB) Helper() {}
+
+func (t *testTB) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *testTB) Log(args ...any) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestAssert(t *testing.T) {
+	var w Writer
+	logger := NewLogger(&w)
+	logger.Error().Str("x", "y").Int("attempt", 2).Msg("request failed")
+
+	if !w.AssertLogged(t, log.ErrorLevel, "", "x", "y") ||
+		!w.AssertLogged(t, log.ErrorLevel, "request failed", "x", "y", "attempt", 2) ||
+		!w.AssertNotLogged(t, log.InfoLevel, "") ||
+		!w.AssertNotLogged(t, log.ErrorLevel, "", "x", "z") {
+		t.Fatalf("assertions must pass")
+	}
+
+	tb := &testTB{}
+	if w.AssertLogged(tb, log.ErrorLevel, "", "x", "z") {
+		t.Fatalf("AssertLogged must fail on mismatched field")
+	}
+	if w.AssertNotLogged(tb, log.ErrorLevel, "request failed") {
+		t.Fatalf("AssertNotLogged must fail on logged entry")
+	}
+	if len(tb.errors) != 2 ||
+		!strings.Contains(tb.errors[0], `no error entry with any message x=z was logged`) ||
+		!strings.Contains(tb.errors[0], `"x":"y"`) ||
+		!strings.Contains(tb.errors[1], `unexpected error entry "request failed" was logged`) {
+		t.Fatalf("wrong assertion errors: %q", tb.errors)
+	}
+}
+
+func TestTestingWriter(t *testing.T) {
+	tb := &testTB{}
+	logger := NewLogger(NewTestingWriter(tb))
+	logger.Info().Str("foo", "bar").Msg("hello")
+
+	if want := `{"time":"2020-01-02T03:04:05.000Z","level":"info","foo":"bar","message":"hello"}`; len(tb.logs) != 1 || tb.logs[0] != want {
+		t.Fatalf("testing writer must log the entry, want %s got %q", want, tb.logs)
+	}
+
+	NewLogger(NewTestingWriter(t)).Info().Msg("routed to t.Log")
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock()
+	if !c.Now().Equal(FixedTime) {
+		t.Fatalf("clock must start at FixedTime: %v", c.Now())
+	}
+	c.Add(time.Second)
+	if !c.Now().Equal(FixedTime.Add(time.Second)) {
+		t.Fatalf("clock must be advanced by Add: %v", c.Now())
+	}
+
+	var w Writer
+	logger := NewLogger(&w)
+	logger.Clock = c.Now
+	logger.Info().Msg("one")
+	c.Set(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger.Info().Msg("two")
+
+	entries := w.Entries()
+	if !strings.HasPrefix(entries[0].String(), `{"time":"2020-01-02T03:04:06.000Z"`) ||
+		!strings.HasPrefix(entries[1].String(), `{"time":"2021-01-01T00:00:00.000Z"`) {
+		t.Fatalf("entries must use the time of clock: %s", entries)
+	}
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	var w Writer
+	logger := NewLogger(&w)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info().Int("i", i).Int("j", j).Msg("concurrent")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if w.Len() != 800 || len(w.Entries().FilterField("i", 3)) != 100 {
+		t.Fatalf("writer must record all concurrent entries, got %d", w.Len())
+	}
+}
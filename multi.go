@@ -2,6 +2,7 @@ package log
 
 import (
 	"io"
+	"sync/atomic"
 )
 
 // MultiWriter is an alias for MultiLevelWriter
@@ -48,7 +49,14 @@ func (w *MultiLevelWriter) Close() (err error) {
 // WriteEntry implements entryWriter.
 func (w *MultiLevelWriter) WriteEntry(e *Entry) (n int, err error) {
 	var err1 error
-	switch e.Level {
+	level := e.Level
+	if level >= 100 {
+		// route the user-defined levels as the built-in levels below them
+		if level = Level(level.rank() / 100); level > noLevel {
+			level = noLevel
+		}
+	}
+	switch level {
 	case noLevel, PanicLevel, FatalLevel, ErrorLevel:
 		if w.ErrorWriter != nil {
 			n, err1 = w.ErrorWriter.WriteEntry(e)
@@ -74,7 +82,7 @@ func (w *MultiLevelWriter) WriteEntry(e *Entry) (n int, err error) {
 		}
 	}
 
-	if w.ConsoleWriter != nil && e.Level >= w.ConsoleLevel {
+	if w.ConsoleWriter != nil && e.Level.rank() >= w.ConsoleLevel.rank() {
 		_, _ = w.ConsoleWriter.WriteEntry(e)
 	}
 
@@ -138,3 +146,48 @@ func (w *MultiIOWriter) WriteEntry(e *Entry) (n int, err error) {
 
 	return
 }
+
+var _ Writer = (*MultiIOWriter)(nil)
+
+// FallbackWriter is a Writer that writes to Fallback when Writer fails.
+type FallbackWriter struct {
+	// Writer specifies the primary writer.
+	Writer Writer
+
+	// Fallback specifies the writer used when Writer returns an error.
+	Fallback Writer
+
+	failures uint64
+}
+
+// Close implements io.Closer, and closes the underlying Writer and Fallback.
+func (w *FallbackWriter) Close() (err error) {
+	for _, writer := range []Writer{w.Writer, w.Fallback} {
+		if closer, ok := writer.(io.Closer); ok {
+			if err1 := closer.Close(); err1 != nil {
+				err = err1
+			}
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer. It returns the error of Fallback if both writers fail.
+func (w *FallbackWriter) WriteEntry(e *Entry) (n int, err error) {
+	n, err = w.Writer.WriteEntry(e)
+	if err == nil {
+		return
+	}
+	atomic.AddUint64(&w.failures, 1)
+	if w.Fallback == nil {
+		return
+	}
+	return w.Fallback.WriteEntry(e)
+}
+
+// Failures returns the number of entries which Writer failed to write.
+func (w *FallbackWriter) Failures() uint64 {
+	return atomic.LoadUint64(&w.failures)
+}
+
+var _ Writer = (*FallbackWriter)(nil)
@@ -135,6 +135,22 @@ func (l Logger) log() log.Logger {
 	return logger
 }
 
+// levelKey returns the level field name of names or log.LevelKey.
+func levelKey(names *log.FieldNames) string {
+	if names != nil && names.Level != "" {
+		return names.Level
+	}
+	return log.LevelKey
+}
+
+// messageKey returns the message field name of names or log.MessageKey.
+func messageKey(names *log.FieldNames) string {
+	if names != nil && names.Message != "" {
+		return names.Message
+	}
+	return log.MessageKey
+}
+
 // Logger returns a scoped OpenTelemetry logger.
 func (p LoggerProvider) Logger(name string, options ...otellog.LoggerOption) otellog.Logger {
 	cfg := otellog.NewLoggerConfig(options...)
@@ -204,7 +220,7 @@ func (l Logger) newEntry(level log.Level, ok bool) *log.Entry {
 		return nil
 	}
 	if level == log.FatalLevel || level == log.PanicLevel {
-		return logger.Log().Str(log.LevelKey, levelString(level))
+		return logger.Log().Str(levelKey(l.Log.FieldNames), levelString(level))
 	}
 	return logger.WithLevel(level)
 }
@@ -229,7 +245,7 @@ func (l Logger) Emit(ctx context.Context, record otellog.Record) {
 	}
 	if !ok {
 		if text := record.SeverityText(); text != "" {
-			e = e.Str(log.LevelKey, text)
+			e = e.Str(levelKey(l.Log.FieldNames), text)
 		}
 	} else if text := record.SeverityText(); text != "" && fields.SeverityText != "" {
 		e = e.Str(fields.SeverityText, text)
@@ -277,8 +293,10 @@ func (l Logger) Emit(ctx context.Context, record otellog.Record) {
 		}
 	}
 
+	e = e.Ctx(ctx)
+
 	if body := record.Body(); !body.Empty() {
-		e = appendValue(e, log.MessageKey, body)
+		e = appendValue(e, messageKey(l.Log.FieldNames), body)
 	}
 	record.WalkAttributes(func(kv otellog.KeyValue) bool {
 		e = appendValue(e, kv.Key, kv.Value)
@@ -287,6 +305,16 @@ func (l Logger) Emit(ctx context.Context, record otellog.Record) {
 	e.Msg("")
 }
 
+// TraceContextExtractor is a log.ContextExtractor that adds trace_id, span_id
+// and trace_flags of the OpenTelemetry span context carried by ctx.
+func TraceContextExtractor(ctx context.Context, e *log.Entry) {
+	if sc := oteltrace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.Str(defaultFieldNames.TraceID, sc.TraceID().String()).
+			Str(defaultFieldNames.SpanID, sc.SpanID().String()).
+			Str(defaultFieldNames.TraceFlags, sc.TraceFlags().String())
+	}
+}
+
 func appendValue(e *log.Entry, key string, value otellog.Value) *log.Entry {
 	switch value.Kind() {
 	case otellog.KindBool:
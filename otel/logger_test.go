@@ -284,3 +284,32 @@ func BenchmarkPhusluLoggerEmitNestedValues(b *testing.B) {
 		logger.Emit(context.Background(), record)
 	}
 }
+
+func TestTraceContextExtractor(t *testing.T) {
+	var b bytes.Buffer
+	logger := log.Logger{
+		Level:             log.DebugLevel,
+		ContextExtractors: []log.ContextExtractor{TraceContextExtractor},
+		Writer:            log.IOWriter{Writer: &b},
+	}
+
+	traceID := oteltrace.TraceID{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	spanID := oteltrace.SpanID{0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17}
+	ctx := oteltrace.ContextWithSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: oteltrace.FlagsSampled,
+	}))
+
+	logger.InfoContext(ctx).Msg("hello from trace context extractor")
+	got := decodeLog(t, &b)
+	if got["trace_id"] != traceID.String() || got["span_id"] != spanID.String() || got["trace_flags"] != "01" {
+		t.Fatalf("trace fields missing: %#v", got)
+	}
+
+	b.Reset()
+	logger.InfoContext(context.Background()).Msg("hello without span")
+	if got := decodeLog(t, &b); got["trace_id"] != nil {
+		t.Fatalf("trace fields must be absent without span: %#v", got)
+	}
+}
@@ -0,0 +1,439 @@
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+)
+
+// Redactor redacts the values of sensitive keys when they are written to entries.
+type Redactor struct {
+	// Keys specifies the keys to redact, they are matched case-insensitively
+	// and may contain the glob wildcards '*' and '?'.
+	// A key without dots matches the field at any depth, e.g. "password" or "*token*".
+	// A key with dots matches the nested path of Dict, Object and Any values,
+	// e.g. "user.password" or "headers.x-*".
+	Keys []string
+
+	// Replacement specifies the string of redacted values, it uses "[REDACTED]" if empty.
+	Replacement string
+
+	// Hash determines if replaces the values with "hmac:" and the first 16 hex digits
+	// of their HMAC-SHA256 keyed by HashKey instead of Replacement, so equal values
+	// remain correlatable.
+	Hash bool
+
+	// HashKey specifies the secret key of Hash. It uses a random key if empty, then
+	// the values are only correlatable within the process.
+	HashKey []byte
+
+	once        sync.Once
+	exact       map[string]struct{}
+	globs       []string
+	paths       [][]string
+	replacement []byte
+	hashes      sync.Pool
+}
+
+func (r *Redactor) init() {
+	r.exact = make(map[string]struct{})
+	for _, key := range r.Keys {
+		key = strings.ToLower(key)
+		switch {
+		case strings.IndexByte(key, '.') >= 0:
+			r.paths = append(r.paths, strings.Split(key, "."))
+		case strings.ContainsAny(key, "*?"):
+			r.globs = append(r.globs, key)
+		default:
+			r.exact[key] = struct{}{}
+		}
+	}
+	replacement := r.Replacement
+	if replacement == "" {
+		replacement = "[REDACTED]"
+	}
+	b, _ := json.Marshal(replacement)
+	r.replacement = b[1 : len(b)-1]
+
+	key := r.HashKey
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		_, _ = rand.Read(key)
+	}
+	r.hashes.New = func() any {
+		return &redactorHash{Hash: hmac.New(sha256.New, key)}
+	}
+}
+
+// redactorHash is the pooled hash of Redactor with its buffer of input and sum.
+type redactorHash struct {
+	hash.Hash
+	buf []byte
+}
+
+// match reports whether the field of dotted path should be redacted.
+func (r *Redactor) match(path string) bool {
+	r.once.Do(r.init)
+
+	var tmp [128]byte
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; 'A' <= c && c <= 'Z' {
+			if len(path) > len(tmp) {
+				path = strings.ToLower(path)
+				break
+			}
+			n := copy(tmp[:], path)
+			for j := i; j < n; j++ {
+				if c := tmp[j]; 'A' <= c && c <= 'Z' {
+					tmp[j] = c + 'a' - 'A'
+				}
+			}
+			path = b2s(tmp[:n])
+			break
+		}
+	}
+
+	key := path[strings.LastIndexByte(path, '.')+1:]
+	if _, ok := r.exact[key]; ok {
+		return true
+	}
+	for _, glob := range r.globs {
+		if globMatch(glob, key) {
+			return true
+		}
+	}
+	if len(r.paths) == 0 || len(key) == len(path) {
+		return false
+	}
+	for _, segments := range r.paths {
+		s, i := path, 0
+		for ; i < len(segments) && s != ""; i++ {
+			n := strings.IndexByte(s, '.')
+			if n < 0 {
+				n = len(s)
+			}
+			if !globMatch(segments[i], s[:n]) {
+				break
+			}
+			if s = s[n:]; s != "" {
+				s = s[1:]
+			}
+		}
+		if i == len(segments) && s == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether s matches the pattern with '*' and '?' wildcards.
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	nextpx, nextsx := -1, -1
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				nextpx, nextsx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if nextsx > 0 && nextsx <= len(s) {
+			px, sx = nextpx, nextsx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// appendRedacted appends the redacted val to dst, without quotes.
+func (r *Redactor) appendRedacted(dst []byte, val string) []byte {
+	r.once.Do(r.init)
+
+	if !r.Hash {
+		return append(dst, r.replacement...)
+	}
+	// hash the copy of val in the pooled buffer, which avoids the allocation of []byte(val).
+	h := r.hashes.Get().(*redactorHash)
+	h.Reset()
+	h.buf = append(h.buf[:0], val...)
+	h.Write(h.buf)
+	h.buf = h.Sum(h.buf[:0])
+	dst = append(dst, "hmac:"...)
+	for _, c := range h.buf[:8] {
+		dst = append(dst, hex[c>>4], hex[c&0x0f])
+	}
+	if cap(h.buf) > bbcap {
+		h.buf = nil
+	}
+	r.hashes.Put(h)
+	return dst
+}
+
+// redactValue replaces the json value of buf[i:] with the quoted redacted string of it.
+func (r *Redactor) redactValue(buf []byte, i int) []byte {
+	var val string
+	if i < len(buf) {
+		val = jsonString(buf[i:])
+	}
+	b := bbpool.Get().(*bb)
+	b.B = append(b.B[:0], '"')
+	b.B = r.appendRedacted(b.B, val)
+	b.B = append(b.B, '"')
+	buf = append(buf[:i], b.B...)
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+	return buf
+}
+
+// redactJSON redacts the json value of buf[i:] which is written under the dotted path.
+func (r *Redactor) redactJSON(buf []byte, i int, path string) []byte {
+	if i >= len(buf) {
+		return buf
+	}
+	if r.match(path) {
+		return r.redactValue(buf, i)
+	}
+	if buf[i] != '{' && buf[i] != '[' {
+		return buf
+	}
+	b := bbpool.Get().(*bb)
+	b.B = append(b.B[:0], buf[i:]...)
+	var tmp [128]byte
+	buf, _ = r.appendJSON(buf[:i], b.B, append(tmp[:0], path...))
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+	return buf
+}
+
+// redactFields redacts the json object members of buf[i:] which are written under the dotted path.
+func (r *Redactor) redactFields(buf []byte, i int, path string) []byte {
+	b := bbpool.Get().(*bb)
+	b.B = append(b.B[:0], buf[i:]...)
+	var tmp [128]byte
+	buf, rest := r.appendMembers(buf[:i], b.B, append(tmp[:0], path...))
+	buf = append(buf, rest...)
+	if cap(b.B) <= bbcap {
+		bbpool.Put(b)
+	}
+	return buf
+}
+
+// appendJSON appends the json value at the beginning of src to dst with the
+// values of matched paths redacted, and returns the rest of src.
+func (r *Redactor) appendJSON(dst, src, path []byte) ([]byte, []byte) {
+	if len(src) == 0 {
+		return dst, src
+	}
+	switch src[0] {
+	case '{':
+		dst = append(dst, '{')
+		dst, src = r.appendMembers(dst, src[1:], path)
+		if len(src) != 0 {
+			dst = append(dst, '}')
+			src = src[1:]
+		}
+	case '[':
+		dst = append(dst, '[')
+		src = src[1:]
+		for len(src) != 0 && src[0] != ']' {
+			switch src[0] {
+			case ',', ' ', '\t', '\r', '\n':
+				dst = append(dst, src[0])
+				src = src[1:]
+			default:
+				dst, src = r.appendJSON(dst, src, path)
+			}
+		}
+		if len(src) != 0 {
+			dst = append(dst, ']')
+			src = src[1:]
+		}
+	default:
+		n := jsonValueLen(src)
+		if n == 0 {
+			n = 1
+		}
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+	}
+	return dst, src
+}
+
+// appendMembers appends the object members of src until '}' or the end of src to dst
+// with the values of matched paths redacted, and returns the rest of src.
+func (r *Redactor) appendMembers(dst, src, path []byte) ([]byte, []byte) {
+	for len(src) != 0 && src[0] != '}' {
+		if src[0] != '"' {
+			dst = append(dst, src[0])
+			src = src[1:]
+			continue
+		}
+		// key
+		n := jsonValueLen(src)
+		if n < 2 {
+			return append(dst, src...), nil
+		}
+		p := path
+		if len(p) != 0 {
+			p = append(p, '.')
+		}
+		p = append(p, src[1:n-1]...)
+		dst = append(dst, src[:n]...)
+		src = src[n:]
+		for len(src) != 0 && src[0] != ':' {
+			dst = append(dst, src[0])
+			src = src[1:]
+		}
+		if len(src) == 0 {
+			break
+		}
+		dst = append(dst, ':')
+		src = src[1:]
+		for len(src) != 0 && (src[0] == ' ' || src[0] == '\t' || src[0] == '\r' || src[0] == '\n') {
+			dst = append(dst, src[0])
+			src = src[1:]
+		}
+		// value
+		if r.match(b2s(p)) {
+			n = jsonValueLen(src)
+			dst = append(dst, '"')
+			dst = r.appendRedacted(dst, jsonString(src[:n]))
+			dst = append(dst, '"')
+			src = src[n:]
+		} else {
+			dst, src = r.appendJSON(dst, src, p)
+		}
+	}
+	return dst, src
+}
+
+// jsonValueLen returns the length of the json value at the beginning of b.
+func jsonValueLen(b []byte) int {
+	depth, instr := 0, false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if instr {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				instr = false
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			instr = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return i
+			}
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(b)
+}
+
+// jsonString returns the content of json string b, or b itself for other json values.
+func jsonString(b []byte) string {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return b2s(b)
+	}
+	for _, c := range b {
+		if c == '\\' {
+			var s string
+			if json.Unmarshal(b, &s) == nil {
+				return s
+			}
+			break
+		}
+	}
+	return b2s(b[1 : len(b)-1])
+}
+
+// redacted adds the field key with the redacted val to the entry.
+func (e *Entry) redacted(key string, val string) *Entry {
+	e.buf = append(e.buf, ',', '"')
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, '"', ':', '"')
+	e.buf = e.redactor.appendRedacted(e.buf, val)
+	e.buf = append(e.buf, '"')
+	return e
+}
+
+// defaultRedactor redacts the fields tagged with `log:",redact"` if the logger has no Redactor.
+var defaultRedactor Redactor
+
+// tagRedactor returns the redactor of the fields tagged with `log:",redact"`, which is
+// the Redactor of logger even if the nested values are being encoded without it.
+func (e *Entry) tagRedactor() *Redactor {
+	switch {
+	case e.redactor != nil:
+		return e.redactor
+	case e.redacting != nil:
+		return e.redacting
+	}
+	return &defaultRedactor
+}
+
+// Redacted adds the field key with value redacted by the Redactor of logger as the
+// keys matched by Redactor.Keys, it writes "[REDACTED]" if the logger has no Redactor.
+// It is used by the MarshalObject methods generated for the fields tagged with `log:",redact"`.
+func (e *Entry) Redacted(key string, value any) *Entry {
+	if e == nil {
+		return nil
+	}
+	redactor := e.redactor
+	e.redactor = e.tagRedactor()
+	e.redactedAny(key, value)
+	e.redactor = redactor
+	return e
+}
+
+// redactedAny adds the field key with the redacted value to the entry.
+func (e *Entry) redactedAny(key string, value any) *Entry {
+	if !e.redactor.Hash {
+		return e.redacted(key, "")
+	}
+	switch value := value.(type) {
+	case string:
+		return e.redacted(key, value)
+	case []byte:
+		return e.redacted(key, b2s(value))
+	default:
+		return e.redacted(key, fmt.Sprint(value))
+	}
+}
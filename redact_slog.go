@@ -0,0 +1,28 @@
+//go:build go1.21
+
+package log
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// ReplaceAttr redacts the attr a within groups, it can be used as
+// slog.HandlerOptions.ReplaceAttr of other slog handlers.
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+	path := a.Key
+	if len(groups) != 0 {
+		path = strings.Join(groups, ".") + "." + a.Key
+	}
+	if !r.match(path) {
+		return a
+	}
+	var val string
+	if r.Hash {
+		val = a.Value.Resolve().String()
+	}
+	return slog.String(a.Key, string(r.appendRedacted(nil, val)))
+}
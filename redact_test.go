@@ -0,0 +1,228 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRedactorMatch(t *testing.T) {
+	redactor := &Redactor{Keys: []string{"password", "*token*", "user.email", "headers.x-*"}}
+
+	cases := []struct {
+		Path  string
+		Match bool
+	}{
+		{"password", true},
+		{"Password", true},
+		{"user.password", true},
+		{"access_token", true},
+		{"TokenID", true},
+		{"passwd", false},
+		{"user.email", true},
+		{"email", false},
+		{"admin.user.email", false},
+		{"headers.X-Api-Key", true},
+		{"headers.accept", false},
+		{"x-api-key", false},
+	}
+
+	for _, c := range cases {
+		if got := redactor.match(c.Path); got != c.Match {
+			t.Errorf("Redactor.match(%#v) = %v, want %v", c.Path, got, c.Match)
+		}
+	}
+}
+
+func TestLoggerRedactor(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"password", "authorization", "*token", "user.name"}},
+	}
+
+	logger.Info().
+		Str("password", "123456").
+		Bytes("Authorization", []byte("Bearer xyz")).
+		Any("refresh_token", 42).
+		Str("user", "alice").
+		Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"password":"[REDACTED]","Authorization":"[REDACTED]","refresh_token":"[REDACTED]","user":"alice"`) {
+		t.Fatalf("redactor must redact keys: %s", s)
+	}
+
+	b.Reset()
+	logger.Info().
+		Fields(Fields{"access_token": "abc"}).
+		KeysAndValues("password", "123456", "foo", "bar").
+		Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"access_token":"[REDACTED]"`) || !strings.Contains(s, `"password":"[REDACTED]","foo":"bar"`) {
+		t.Fatalf("redactor must redact fields and keys and values: %s", s)
+	}
+
+	b.Reset()
+	logger.Info().
+		Dict("user", NewContext(nil).Str("name", "alice").Str("password", "123456").Int("age", 30).Value()).
+		Object("owner", &testMarshalObject{1, "bob"}).
+		Any("headers", map[string]any{"Authorization": "Bearer xyz", "Accept": []string{"*/*"}}).
+		Interface("config", struct {
+			User struct{ Name string } `json:"user"`
+			Port int                   `json:"port"`
+		}{User: struct{ Name string }{"carol"}, Port: 8080}).
+		Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"user":{"name":"[REDACTED]","password":"[REDACTED]","age":30}`) ||
+		!strings.Contains(s, `"owner":{"id":1,"name":"bob"}`) ||
+		!strings.Contains(s, `"headers":{"Accept":["*/*"],"Authorization":"[REDACTED]"}`) ||
+		!strings.Contains(s, `"config":{"user":{"Name":"carol"},"port":8080}`) {
+		t.Fatalf("redactor must redact nested paths: %s", s)
+	}
+
+	b.Reset()
+	logger.Categorized("redact").Info().Str("password", "123456").Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"password":"[REDACTED]"`) {
+		t.Fatalf("categorized logger must inherit redactor: %s", s)
+	}
+}
+
+func TestRedactorHash(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"email", "user.email", "emails"}, Hash: true, HashKey: []byte("secret")},
+	}
+
+	logger.Info().
+		Str("email", "alice@example.com").
+		Dict("user", NewContext(nil).Str("email", "alice@example.com").Value()).
+		Any("other", map[string]string{"email": "bob@example.com"}).
+		Strs("emails", []string{"alice@example.com", "bob@example.com"}).
+		Msg("hello redactor")
+	// hmac-sha256("secret", "alice@example.com")
+	const alice = "hmac:a398d49ce1980b36"
+	if s := b.String(); strings.Count(s, alice) != 3 || !strings.Contains(s, `"other":{"email":"hmac:`) || strings.Contains(s, "@example.com") {
+		t.Fatalf("redactor must hash values: %s", s)
+	}
+
+	// the random key is not the unkeyed sha256
+	b.Reset()
+	logger.Redactor = &Redactor{Keys: []string{"email"}, Hash: true}
+	logger.Info().Str("email", "alice@example.com").Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"email":"hmac:`) || strings.Contains(s, alice) || strings.Contains(s, "ff8d9819fc0e12bf") {
+		t.Fatalf("redactor must hash values with a random key: %s", s)
+	}
+}
+
+func TestRedactorStrs(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"passwords"}},
+	}
+
+	logger.Info().Strs("passwords", []string{"123456", "654321"}).Strs("names", []string{"alice"}).Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"passwords":["[REDACTED]","[REDACTED]"]`) || !strings.Contains(s, `"names":["alice"]`) {
+		t.Fatalf("redactor must redact string slices: %s", s)
+	}
+}
+
+func TestRedactorReplacement(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:    InfoLevel,
+		Writer:   &IOWriter{Writer: &b},
+		Redactor: &Redactor{Keys: []string{"password"}, Replacement: `"***"`},
+	}
+
+	logger.Info().Str("password", "123456").Msg("hello redactor")
+	if s := b.String(); !strings.Contains(s, `"password":"\"***\""`) {
+		t.Fatalf("redactor must escape replacement: %s", s)
+	}
+}
+
+type redactTestUser struct {
+	Name     string `json:"name"`
+	Email    string `json:"email" log:",redact"`
+	Password string `json:"password"`
+}
+
+func (u *redactTestUser) MarshalObject(e *Entry) {
+	e.Str("name", u.Name)
+	e.Redacted("email", u.Email)
+	e.Str("password", u.Password)
+}
+
+func TestRedactorTag(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+	}
+	user := &redactTestUser{Name: "alice", Email: "alice@example.com", Password: "123456"}
+
+	// the tagged fields are written as "[REDACTED]" without Redactor
+	logger.Info().Any("any", *user).Object("object", user).Msg("hello redactor")
+	if s := b.String(); strings.Count(s, `"email":"[REDACTED]"`) != 2 || strings.Contains(s, "@example.com") {
+		t.Fatalf("tagged fields must be redacted: %s", s)
+	}
+
+	// the tagged fields and the keys are redacted by the same Redactor
+	for _, redactor := range []*Redactor{
+		{Keys: []string{"password", "mail"}, Replacement: "***"},
+		{Keys: []string{"password", "mail"}, Hash: true, HashKey: []byte("secret")},
+	} {
+		b.Reset()
+		logger.Redactor = redactor
+		logger.Info().Any("any", *user).Object("object", user).Str("mail", "alice@example.com").Msg("hello redactor")
+		var m struct {
+			Any    redactTestUser
+			Object redactTestUser
+			Mail   string
+		}
+		if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+			t.Fatalf("unmarshal error: %+v", err)
+		}
+		want := redactor.Replacement
+		if redactor.Hash {
+			// hmac-sha256("secret", "alice@example.com")
+			want = "hmac:a398d49ce1980b36"
+		}
+		if m.Any.Email != want || m.Object.Email != want || m.Mail != want || m.Any.Password != m.Object.Password || m.Any.Password == "123456" {
+			t.Fatalf("tagged fields must be redacted by the redactor of logger: %s", b.String())
+		}
+	}
+}
+
+func BenchmarkLoggerRedactor(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Writer:     IOWriter{io.Discard},
+		Redactor:   &Redactor{Keys: []string{"password", "*token*", "user.email"}},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Str("Password", "123456").Msg("hello world")
+	}
+}
+
+func BenchmarkLoggerRedactorHash(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Writer:     IOWriter{io.Discard},
+		Redactor:   &Redactor{Keys: []string{"password", "*token*", "user.email"}, Hash: true, HashKey: []byte("secret")},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Str("Password", "123456").Msg("hello world")
+	}
+}
@@ -0,0 +1,221 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler defines an interface to a log sampler.
+// It is consulted by the level methods of Logger before an entry is created.
+type Sampler interface {
+	// Sample returns true if the entry of level should be logged.
+	Sample(level Level) bool
+}
+
+// MessageSampler defines an interface to a log sampler which is keyed by message.
+// Because the message is passed to Msg, Msgf and Msgs after the header and fields
+// of entry are written, SampleMessage can only be consulted by them after Sample,
+// and the dropped entries are put back to pool without being written. Use a
+// Sampler to drop the entries before the header work.
+type MessageSampler interface {
+	Sampler
+
+	// SampleMessage returns true if the entry of level with msg should be logged.
+	SampleMessage(level Level, msg string) bool
+}
+
+// RandomSampler is a Sampler that logs entries randomly by Ratio.
+type RandomSampler struct {
+	// Ratio specifies the ratio of logged entries in [0, 1].
+	Ratio float64
+
+	dropped uint64
+}
+
+// Sample implements Sampler.
+func (s *RandomSampler) Sample(level Level) bool {
+	if Fastrandn(1<<24) < uint32(s.Ratio*(1<<24)) {
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+// Dropped returns the number of entries sampled out.
+func (s *RandomSampler) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// BurstSampler is a Sampler that lets Burst entries pass per Period,
+// then delegates to NextSampler or drops the entries if NextSampler is nil.
+type BurstSampler struct {
+	// Burst specifies the maximum number of entries per Period.
+	Burst uint32
+
+	// Period specifies the time window of Burst. If it is zero, the window is
+	// never reset and only the first Burst entries pass.
+	Period time.Duration
+
+	// NextSampler specifies the sampler used once the burst is exceeded.
+	NextSampler Sampler
+
+	counter uint32
+	resetAt int64
+	dropped uint64
+}
+
+// Sample implements Sampler.
+func (s *BurstSampler) Sample(level Level) bool {
+	if s.Burst > 0 && s.allow() {
+		return true
+	}
+	if s.NextSampler != nil {
+		if s.NextSampler.Sample(level) {
+			return true
+		}
+		if _, ok := s.NextSampler.(droppedCounter); ok {
+			// counted by NextSampler
+			return false
+		}
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+// allow reports whether the entry is within the burst of current window.
+func (s *BurstSampler) allow() bool {
+	if s.Period <= 0 {
+		// the counter is never reset, so it stops at Burst
+		for {
+			n := atomic.LoadUint32(&s.counter)
+			if n >= s.Burst {
+				return false
+			}
+			if atomic.CompareAndSwapUint32(&s.counter, n, n+1) {
+				return true
+			}
+		}
+	}
+	now := timeNow().UnixNano()
+	resetAt := atomic.LoadInt64(&s.resetAt)
+	if now > resetAt {
+		if atomic.CompareAndSwapInt64(&s.resetAt, resetAt, now+int64(s.Period)) {
+			atomic.StoreUint32(&s.counter, 1)
+			return true
+		}
+	}
+	return atomic.AddUint32(&s.counter, 1) <= s.Burst
+}
+
+// Dropped returns the number of entries sampled out, including the entries dropped by NextSampler.
+func (s *BurstSampler) Dropped() uint64 {
+	n := atomic.LoadUint64(&s.dropped)
+	if next, ok := s.NextSampler.(droppedCounter); ok {
+		n += next.Dropped()
+	}
+	return n
+}
+
+// droppedCounter is implemented by the samplers counting the dropped entries.
+type droppedCounter interface {
+	Dropped() uint64
+}
+
+// ThereafterSampler is a MessageSampler that logs the First entries with the
+// same level and message per Interval, then every Thereafter-th entry. As the
+// message is only known by Msg, the decision is made after the header of entry
+// is written, see MessageSampler.
+//
+// Messages are hashed into a fixed number of buckets, so different messages
+// may share a counter occasionally.
+type ThereafterSampler struct {
+	// First specifies the number of entries logged per Interval.
+	First uint64
+
+	// Thereafter specifies that every Thereafter-th entry is logged after First.
+	// If it is zero, all entries after First are dropped.
+	Thereafter uint64
+
+	// Interval specifies the time window of counters, one second if empty.
+	Interval time.Duration
+
+	dropped  uint64
+	once     sync.Once
+	counters *[noLevel + 1][thereafterBuckets]thereafterCounter
+}
+
+const thereafterBuckets = 1024
+
+type thereafterCounter struct {
+	resetAt int64
+	count   uint64
+}
+
+// Sample implements Sampler, the decision is deferred to SampleMessage.
+func (s *ThereafterSampler) Sample(level Level) bool {
+	return true
+}
+
+// SampleMessage implements MessageSampler.
+func (s *ThereafterSampler) SampleMessage(level Level, msg string) bool {
+	s.once.Do(func() {
+		s.counters = new([noLevel + 1][thereafterBuckets]thereafterCounter)
+	})
+
+	if level > noLevel {
+		level = noLevel
+	}
+	// fnv-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= 16777619
+	}
+	c := &s.counters[level][h%thereafterBuckets]
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	now := timeNow().UnixNano()
+	var n uint64
+	resetAt := atomic.LoadInt64(&c.resetAt)
+	if now > resetAt && atomic.CompareAndSwapInt64(&c.resetAt, resetAt, now+int64(interval)) {
+		atomic.StoreUint64(&c.count, 1)
+		n = 1
+	} else {
+		n = atomic.AddUint64(&c.count, 1)
+	}
+
+	if n <= s.First || (s.Thereafter > 0 && (n-s.First)%s.Thereafter == 0) {
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+// Dropped returns the number of entries sampled out.
+func (s *ThereafterSampler) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// sampleout returns true if the sampler of logger drops the entry of level.
+func (l *Logger) sampleout(level Level) bool {
+	return l.Sampler != nil && !l.Sampler.Sample(level)
+}
+
+// sampleout returns true if the message sampler drops the entry, and puts the entry back to pool.
+func (e *Entry) sampleout(msg string) bool {
+	if e.sampler.SampleMessage(e.Level, msg) {
+		return false
+	}
+	if cap(e.buf) <= bbcap {
+		epool.Put(e)
+	}
+	return true
+}
+
+var _ Sampler = (*RandomSampler)(nil)
+var _ Sampler = (*BurstSampler)(nil)
+var _ MessageSampler = (*ThereafterSampler)(nil)
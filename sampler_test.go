@@ -0,0 +1,135 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRandomSampler(t *testing.T) {
+	var b bytes.Buffer
+	sampler := &RandomSampler{Ratio: 0.1}
+	logger := Logger{
+		Level:   InfoLevel,
+		Sampler: sampler,
+		Writer:  &IOWriter{Writer: &b},
+	}
+
+	for i := 0; i < 10000; i++ {
+		logger.Info().Int("i", i).Msg("hello random sampler")
+	}
+
+	n := strings.Count(b.String(), "\n")
+	if n < 500 || n > 1500 {
+		t.Fatalf("random sampler logged %d entries, want about 1000", n)
+	}
+	if uint64(n)+sampler.Dropped() != 10000 {
+		t.Fatalf("random sampler dropped %d entries, logged %d entries", sampler.Dropped(), n)
+	}
+}
+
+func TestBurstSampler(t *testing.T) {
+	var b bytes.Buffer
+	sampler := &BurstSampler{Burst: 3, Period: time.Hour}
+	logger := Logger{
+		Level:   InfoLevel,
+		Sampler: sampler,
+		Writer:  &IOWriter{Writer: &b},
+	}
+
+	for i := 0; i < 10; i++ {
+		logger.Info().Int("i", i).Msg("hello burst sampler")
+	}
+
+	if n := strings.Count(b.String(), "\n"); n != 3 {
+		t.Fatalf("burst sampler logged %d entries, want 3", n)
+	}
+	if n := sampler.Dropped(); n != 7 {
+		t.Fatalf("burst sampler dropped %d entries, want 7", n)
+	}
+
+	b.Reset()
+	sampler.NextSampler = &RandomSampler{Ratio: 1}
+	logger.Info().Msg("hello next sampler")
+	if n := strings.Count(b.String(), "\n"); n != 1 {
+		t.Fatalf("burst sampler must delegates to next sampler: %s", b.String())
+	}
+
+	// the entries dropped by next sampler are counted once
+	next := &RandomSampler{Ratio: 0}
+	sampler.NextSampler = next
+	for i := 0; i < 5; i++ {
+		logger.Info().Msg("hello next sampler")
+	}
+	if n, m := sampler.Dropped(), next.Dropped(); n != 12 || m != 5 {
+		t.Fatalf("burst sampler dropped %d entries, next sampler dropped %d entries, want 12 and 5", n, m)
+	}
+}
+
+func TestBurstSamplerNoPeriod(t *testing.T) {
+	var b bytes.Buffer
+	sampler := &BurstSampler{Burst: 3}
+	logger := Logger{
+		Level:   InfoLevel,
+		Sampler: sampler,
+		Writer:  &IOWriter{Writer: &b},
+	}
+
+	for i := 0; i < 10; i++ {
+		logger.Info().Int("i", i).Msg("hello burst sampler")
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := strings.Count(b.String(), "\n"); n != 3 || sampler.Dropped() != 7 {
+		t.Fatalf("burst sampler without period logged %d entries, want 3: %s", n, b.String())
+	}
+}
+
+func TestThereafterSampler(t *testing.T) {
+	var b bytes.Buffer
+	sampler := &ThereafterSampler{First: 2, Thereafter: 3, Interval: time.Hour}
+	logger := Logger{
+		Level:   InfoLevel,
+		Sampler: sampler,
+		Writer:  &IOWriter{Writer: &b},
+	}
+
+	for i := 0; i < 11; i++ {
+		logger.Info().Int("i", i).Msg("hello thereafter sampler")
+		logger.Warn().Int("i", i).Msgf("hello %s sampler", "thereafter")
+	}
+
+	// per level and message: 1, 2, 5, 8, 11
+	if n := strings.Count(b.String(), `"level":"info"`); n != 5 {
+		t.Fatalf("thereafter sampler logged %d info entries, want 5: %s", n, b.String())
+	}
+	if n := strings.Count(b.String(), `"level":"warn"`); n != 5 {
+		t.Fatalf("thereafter sampler logged %d warn entries, want 5: %s", n, b.String())
+	}
+	if n := sampler.Dropped(); n != 12 {
+		t.Fatalf("thereafter sampler dropped %d entries, want 12", n)
+	}
+
+	b.Reset()
+	logger.Info().Msg("another message")
+	if !strings.Contains(b.String(), "another message") {
+		t.Fatalf("thereafter sampler must be keyed by message: %s", b.String())
+	}
+}
+
+func BenchmarkLoggerSampler(b *testing.B) {
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Level:      DebugLevel,
+		Sampler:    &BurstSampler{Burst: 100, Period: time.Second},
+		Writer:     IOWriter{io.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Msg("hello world")
+	}
+}
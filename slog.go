@@ -48,11 +48,17 @@ func slogJSONAttrEval(e *Entry, a slog.Attr) *Entry {
 		e.buf = append(e.buf, a.Key...)
 		e.buf = append(e.buf, '"', ':')
 		i := len(e.buf)
+		redactor := e.redactor
+		e.redactor = nil
 		for _, attr := range attrs {
 			e = slogJSONAttrEval(e, attr)
 		}
+		e.redactor = redactor
 		e.buf[i] = '{'
 		e.buf = append(e.buf, '}')
+		if redactor != nil {
+			e.buf = redactor.redactJSON(e.buf, i, a.Key)
+		}
 		return e
 	case slog.KindAny:
 		return e.Any(a.Key, value.Any())
@@ -66,9 +72,11 @@ type slogJSONHandler struct {
 	entry    Entry
 	grouping bool
 	groups   int
+	prefix   string // dotted group path for redactor
 
-	writer  io.Writer
-	options *slog.HandlerOptions
+	writer   io.Writer
+	options  *slog.HandlerOptions
+	redactor *Redactor
 }
 
 func (h *slogJSONHandler) Enabled(_ context.Context, level slog.Level) bool {
@@ -80,9 +88,16 @@ func (h slogJSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		return &h
 	}
 	i := len(h.entry.buf)
+	if h.prefix != "" {
+		h.entry.redactor = nil
+	}
 	for _, attr := range attrs {
 		h.entry = *slogJSONAttrEval(&h.entry, attr)
 	}
+	if h.prefix != "" {
+		h.entry.redactor = h.redactor
+		h.entry.buf = h.redactor.redactFields(h.entry.buf, i, h.prefix)
+	}
 	if h.grouping {
 		h.entry.buf[i] = '{'
 	}
@@ -104,6 +119,12 @@ func (h slogJSONHandler) WithGroup(name string) slog.Handler {
 	h.entry.buf = append(h.entry.buf, '"', ':')
 	h.grouping = true
 	h.groups++
+	if h.redactor != nil {
+		if h.prefix != "" {
+			h.prefix += "."
+		}
+		h.prefix += name
+	}
 	return &h
 }
 
@@ -123,6 +144,9 @@ var slogTimeHeaderPointers struct {
 func (h *slogJSONHandler) Handle(_ context.Context, r slog.Record) error {
 	e := epool.Get().(*Entry)
 	e.buf = e.buf[:0]
+	e.errchain = false
+	e.redactor = h.redactor
+	e.enc = JSONEncoding
 
 	e.buf = append(e.buf, '{')
 
@@ -270,10 +294,17 @@ func (h *slogJSONHandler) Handle(_ context.Context, r slog.Record) error {
 	i := len(e.buf)
 
 	// attrs
+	if h.prefix != "" {
+		e.redactor = nil
+	}
 	r.Attrs(func(attr slog.Attr) bool {
 		e = slogJSONAttrEval(e, attr)
 		return true
 	})
+	if h.prefix != "" {
+		e.redactor = h.redactor
+		e.buf = e.redactor.redactFields(e.buf, i, h.prefix)
+	}
 
 	// rollback helper
 	lastindex := func(buf []byte) int {
@@ -356,14 +387,29 @@ func (h *slogLevelvarHandler) Handle(ctx context.Context, r slog.Record) error {
 
 // SlogNewJSONHandler returns a drop-in replacement of slog.NewJSONHandler.
 func SlogNewJSONHandler(writer io.Writer, options *slog.HandlerOptions) slog.Handler {
+	return SlogNewRedactedJSONHandler(writer, options, nil)
+}
+
+// SlogNewRedactedJSONHandler returns a drop-in replacement of slog.NewJSONHandler
+// which redacts the attrs by redactor.
+func SlogNewRedactedJSONHandler(writer io.Writer, options *slog.HandlerOptions, redactor *Redactor) slog.Handler {
 	if options != nil && options.ReplaceAttr != nil {
 		// TODO: implement ReplaceAttr in a new handler.
+		if redactor != nil {
+			opts, replace := *options, options.ReplaceAttr
+			opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+				return redactor.ReplaceAttr(groups, replace(groups, a))
+			}
+			options = &opts
+		}
 		return slog.NewJSONHandler(writer, options)
 	}
 
 	handler := &slogJSONHandler{
-		writer:  writer,
-		options: options,
+		entry:    Entry{redactor: redactor},
+		writer:   writer,
+		options:  options,
+		redactor: redactor,
 	}
 
 	if handler.options == nil || handler.options.Level == nil {
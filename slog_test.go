@@ -3,8 +3,10 @@
 package log
 
 import (
+	"bytes"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -55,3 +57,29 @@ func TestSlogJsonHandlerAny(t *testing.T) {
 
 	logger.Info("hello from slog any", "bad object", logger.Info)
 }
+
+func TestSlogRedactedJSONHandler(t *testing.T) {
+	var b bytes.Buffer
+	redactor := &Redactor{Keys: []string{"password", "req.authorization"}}
+	logger := slog.New(SlogNewRedactedJSONHandler(&b, nil, redactor))
+
+	logger.Info("hello", "password", "123456", slog.Group("req", "authorization", "Bearer xyz", "path", "/"))
+	if s := b.String(); !strings.Contains(s, `"password":"[REDACTED]","req":{"authorization":"[REDACTED]","path":"/"}`) {
+		t.Fatalf("slog json handler must redact attrs: %s", s)
+	}
+
+	b.Reset()
+	logger.WithGroup("req").With("authorization", "Bearer xyz").Info("hello", "password", "123456")
+	if s := b.String(); !strings.Contains(s, `"req":{"authorization":"[REDACTED]","password":"[REDACTED]"}`) {
+		t.Fatalf("slog json handler must redact attrs within groups: %s", s)
+	}
+
+	b.Reset()
+	logger = slog.New(SlogNewRedactedJSONHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr { return a },
+	}, redactor))
+	logger.WithGroup("req").Info("hello", "authorization", "Bearer xyz")
+	if s := b.String(); !strings.Contains(s, `"req":{"authorization":"[REDACTED]"}`) {
+		t.Fatalf("slog json handler must redact attrs by ReplaceAttr: %s", s)
+	}
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackOptions specifies the options of structured stack traces.
+type StackOptions struct {
+	// Depth limits the number of frames, it uses 32 if empty.
+	Depth int
+
+	// FullPath determines if adds the full /path/to/file and the full function name of frames.
+	FullPath bool
+
+	// Runtime determines if keeps the frames of runtime and internal packages.
+	Runtime bool
+}
+
+// StackFrames adds the stack trace of current goroutine as an array of
+// {"func","file","line"} objects under the stack field.
+func (e *Entry) StackFrames(opts StackOptions) *Entry {
+	if e == nil {
+		return nil
+	}
+
+	e.stackframes(2, opts)
+	return e
+}
+
+// stackframes adds the stack trace under the stack field, skips the frames of
+// stackframes and its skip-1 callers, and the leading frames of std log and slog.
+func (e *Entry) stackframes(skip int, opts StackOptions) {
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = 32
+	}
+	var tmp [64]uintptr
+	pcs := tmp[:]
+	if depth > len(pcs) {
+		pcs = make([]uintptr, depth)
+	}
+	pcs = pcs[:runtime.Callers(skip+1, pcs)]
+	// skip the leading frames of std log and slog
+	for len(pcs) != 0 {
+		if fn := runtime.FuncForPC(pcs[0] - 1); fn != nil {
+			if name := fn.Name(); strings.HasPrefix(name, "log.") || strings.HasPrefix(name, "log/slog.") {
+				pcs = pcs[1:]
+				continue
+			}
+		}
+		break
+	}
+
+	e.buf = append(e.buf, ",\""...)
+	e.buf = append(e.buf, e.names.stack()...)
+	e.buf = append(e.buf, "\":"...)
+	e.frames(pcs, opts)
+}
+
+// frames appends pcs as a json array of {"func","file","line"} objects.
+func (e *Entry) frames(pcs []uintptr, opts StackOptions) {
+	depth := opts.Depth
+	if depth <= 0 {
+		depth = 32
+	}
+	e.buf = append(e.buf, '[')
+	frames := runtime.CallersFrames(pcs)
+	for n := 0; n < depth; {
+		frame, more := frames.Next()
+		if frame.PC == 0 {
+			break
+		}
+		file, name := frame.File, frame.Function
+		if !opts.Runtime && (strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "internal/")) {
+			if !more {
+				break
+			}
+			continue
+		}
+		if !opts.FullPath {
+			file, name = trimFileName(file), trimFuncName(name)
+		}
+		if n != 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = append(e.buf, "{\"func\":\""...)
+		e.string(name)
+		e.buf = append(e.buf, "\",\"file\":\""...)
+		e.string(file)
+		e.buf = append(e.buf, "\",\"line\":"...)
+		e.buf = strconv.AppendInt(e.buf, int64(frame.Line), 10)
+		e.buf = append(e.buf, '}')
+		n++
+		if !more {
+			break
+		}
+	}
+	e.buf = append(e.buf, ']')
+}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testStackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func decodeStackFrames(t *testing.T, b []byte) []testStackFrame {
+	t.Helper()
+	var got struct {
+		Stack []testStackFrame `json:"stack"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %+v", b, err)
+	}
+	return got.Stack
+}
+
+func TestEntryStackFrames(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: &IOWriter{Writer: &b},
+	}
+
+	logger.Info().StackFrames(StackOptions{Depth: 1}).Msg("hello stack frames")
+	frames := decodeStackFrames(t, b.Bytes())
+	if len(frames) != 1 {
+		t.Fatalf("stack frames must be limited by depth: %s", b.String())
+	}
+	if frame := frames[0]; frame.Func != "log.TestEntryStackFrames" || !strings.HasSuffix(frame.File, "/stack_test.go") || strings.Count(frame.File, "/") != 1 || frame.Line == 0 {
+		t.Fatalf("bad stack frame: %+v", frame)
+	}
+
+	b.Reset()
+	logger.Info().StackFrames(StackOptions{FullPath: true, Runtime: true}).Msg("hello full stack frames")
+	frames = decodeStackFrames(t, b.Bytes())
+	if frame := frames[0]; frame.Func != "github.com/phuslu/log.TestEntryStackFrames" || !strings.HasPrefix(frame.File, "/") {
+		t.Fatalf("bad full stack frame: %+v", frame)
+	}
+	if frame := frames[len(frames)-1]; frame.Func != "runtime.goexit" {
+		t.Fatalf("runtime frames must be kept: %+v", frame)
+	}
+
+	b.Reset()
+	logger.Info().StackFrames(StackOptions{}).Msg("hello stack frames")
+	for _, frame := range decodeStackFrames(t, b.Bytes()) {
+		if strings.HasPrefix(frame.Func, "runtime.") {
+			t.Fatalf("runtime frames must be skipped: %+v", frame)
+		}
+	}
+}
+
+func TestLoggerStackLevel(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:      InfoLevel,
+		StackLevel: ErrorLevel,
+		Writer:     &IOWriter{Writer: &b},
+	}
+
+	logger.Warn().Msg("hello warn")
+	if strings.Contains(b.String(), `"stack"`) {
+		t.Fatalf("stack must not be added below StackLevel: %s", b.String())
+	}
+
+	b.Reset()
+	logger.Error().Msg("hello error")
+	if frames := decodeStackFrames(t, b.Bytes()); len(frames) == 0 || frames[0].Func != "log.TestLoggerStackLevel" {
+		t.Fatalf("stack must start from the caller: %s", b.String())
+	}
+
+	b.Reset()
+	logger.StackLevel = InfoLevel
+	logger.Std("", 0).Print("hello std log")
+	if frames := decodeStackFrames(t, b.Bytes()); len(frames) == 0 || frames[0].Func != "log.TestLoggerStackLevel" {
+		t.Fatalf("stack of std log must start from the caller: %s", b.String())
+	}
+}
+
+func TestConsoleWriterStackFrames(t *testing.T) {
+	var b bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: &ConsoleWriter{Writer: &b},
+	}
+
+	logger.Info().StackFrames(StackOptions{Depth: 1}).Msg("hello console stack frames")
+	if s := b.String(); !strings.Contains(s, "\nlog.TestConsoleWriterStackFrames\n\t") {
+		t.Fatalf("console writer must render stack frames: %s", s)
+	}
+}
@@ -112,6 +112,9 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 		priority = '1' // LOG_ALERT
 	default:
 		priority = '6' // LOG_INFO
+		if c := customLevel(e.Level); c != nil && 0 <= c.Priority && c.Priority <= 7 {
+			priority = '0' + byte(c.Priority)
+		}
 	}
 
 	e1 := epool.Get().(*Entry)
@@ -139,7 +142,14 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 	e1.buf = strconv.AppendInt(e1.buf, int64(pid), 10)
 	e1.buf = append(e1.buf, ']', ':', ' ')
 	e1.buf = append(e1.buf, w.Marker...)
-	e1.buf = append(e1.buf, e.buf...)
+	if b := e.jsonbuf(); b != nil {
+		e1.buf = append(e1.buf, b.B...)
+		if cap(b.B) <= bbcap {
+			bbpool.Put(b)
+		}
+	} else {
+		e1.buf = append(e1.buf, e.buf...)
+	}
 
 	w.mu.Lock()
 	defer w.mu.Unlock()
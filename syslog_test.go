@@ -5,6 +5,7 @@ package log
 import (
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -119,3 +120,70 @@ func TestSyslogWriterUnix(t *testing.T) {
 	_, err = wlprintf(w, InfoLevel, "a long long long long message again.\n")
 	t.Logf("write syslog writer error: %+v", err)
 }
+
+func TestSyslogWriterCustomLevel(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w := &SyslogWriter{
+		Network: "udp",
+		Address: conn.LocalAddr().String(),
+		Tag:     "test",
+		Dial:    net.Dial,
+	}
+	defer w.Close()
+
+	_, err = wlprintf(w, testNoticeLevel, `{"time":"2019-07-10T05:35:54.277Z","level":"notice","message":"hello notice"}`+"\n")
+	if err != nil {
+		t.Fatalf("write syslog writer error: %+v", err)
+	}
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read syslog packet error: %+v", err)
+	}
+	if s := string(buf[:n]); s[:3] != "<5>" {
+		t.Fatalf("syslog priority of custom level must be 5: %s", s)
+	}
+}
+
+func TestSyslogWriterCBOR(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w := &SyslogWriter{
+		Network: "udp",
+		Address: conn.LocalAddr().String(),
+		Tag:     "test",
+		Marker:  "@cee:",
+		Dial:    net.Dial,
+	}
+	defer w.Close()
+
+	for _, enc := range []Encoding{CBOREncoding, LogfmtEncoding} {
+		logger := Logger{
+			Level:    InfoLevel,
+			Encoding: enc,
+			Writer:   w,
+		}
+		logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello syslog")
+
+		buf := make([]byte, 1024)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read syslog packet error: %+v", err)
+		}
+		if s := string(buf[:n]); !strings.Contains(s, `@cee:{"time":"`) || !strings.HasSuffix(s, `"level":"info","foo":"bar","n":42,"message":"hello syslog"}`+"\n") {
+			t.Fatalf("syslog writer must send %v entry as JSON: %q", enc, s)
+		}
+	}
+}